@@ -0,0 +1,4 @@
+#!/bin/sh
+set -e
+
+go build -o bin/{{ .Name }} ./cmd
@@ -1,23 +1,40 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"github.com/goccy/go-yaml"
+	"github.com/gttp-cli/gttp/pkg/history"
 	"github.com/gttp-cli/gttp/pkg/model"
 	"github.com/gttp-cli/gttp/pkg/parser"
 	"github.com/gttp-cli/gttp/pkg/utils"
+	"github.com/gttp-cli/gttp/pkg/values"
+	"github.com/gttp-cli/gttp/pkg/writer"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	clip "golang.design/x/clipboard"
-	"os"
+	"path/filepath"
+	"strings"
 )
 
 func init() {
 	rootCmd.Flags().StringP("url", "u", "", "Fetch template from URL")
-	rootCmd.Flags().StringP("file", "f", "", "Fetch template from file")
+	rootCmd.Flags().StringP("file", "f", "", "Fetch template from file or directory")
 	rootCmd.Flags().StringP("output", "o", "", "Output file")
+	rootCmd.Flags().String("output-dir", "", "Output directory for the files of the template")
+	rootCmd.Flags().String("on-conflict", string(writer.Overwrite), "What to do if an output file already exists: overwrite, skip, fail, prompt or diff")
+	rootCmd.Flags().Bool("dry-run", false, "Print what would be written without writing any file")
 	rootCmd.Flags().BoolP("clipboard", "c", false, "Copy output to clipboard")
 	rootCmd.Flags().BoolP("silent", "s", false, "Silent mode")
 	rootCmd.Flags().BoolP("debug", "d", false, "Print debug information")
+	rootCmd.Flags().StringSliceP("values", "v", nil, "Read variable values from YAML or JSON files")
+	rootCmd.Flags().StringArray("set", nil, "Set a variable value, e.g. Name=value or Users[0].Name=value")
+	rootCmd.Flags().StringArray("set-string", nil, "Set a variable value without type coercion")
+	rootCmd.Flags().String("env-prefix", "", "Read variable values from environment variables with this prefix, e.g. GTTP_")
+	rootCmd.Flags().String("record", "", "Write all variable values to an answers file")
+	rootCmd.Flags().String("replay", "", "Read variable values from an answers file, unknown variables are ignored")
+	rootCmd.Flags().Bool("no-history", false, "Do not offer previously entered values as defaults and do not store entered values")
+	rootCmd.Flags().Bool("no-input", false, "Do not ask for input, fail if a variable has no value")
 }
 
 var rootCmd = &cobra.Command{
@@ -27,9 +44,20 @@ var rootCmd = &cobra.Command{
 		url, _ := cmd.Flags().GetString("url")
 		file, _ := cmd.Flags().GetString("file")
 		output, _ := cmd.Flags().GetString("output")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		onConflict, _ := cmd.Flags().GetString("on-conflict")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		silent, _ := cmd.Flags().GetBool("silent")
 		clipboard, _ := cmd.Flags().GetBool("clipboard")
 		debug, _ := cmd.Flags().GetBool("debug")
+		valueFiles, _ := cmd.Flags().GetStringSlice("values")
+		setValues, _ := cmd.Flags().GetStringArray("set")
+		setStringValues, _ := cmd.Flags().GetStringArray("set-string")
+		envPrefix, _ := cmd.Flags().GetString("env-prefix")
+		record, _ := cmd.Flags().GetString("record")
+		replay, _ := cmd.Flags().GetString("replay")
+		noHistory, _ := cmd.Flags().GetBool("no-history")
+		noInput, _ := cmd.Flags().GetBool("no-input")
 
 		// Do not allow both URL and file flags to be set
 		if url != "" && file != "" {
@@ -41,11 +69,25 @@ var rootCmd = &cobra.Command{
 			return fmt.Errorf("must use either URL or file flag")
 		}
 
+		conflictMode, err := writer.ParseConflictMode(onConflict)
+		if err != nil {
+			return err
+		}
+		w := writer.New(conflictMode, dryRun)
+
+		// Do not ask whether to overwrite files in non-interactive mode
+		if noInput && !dryRun && (conflictMode == writer.Prompt || conflictMode == writer.Diff) {
+			return fmt.Errorf("cannot use on-conflict %s with no-input", conflictMode)
+		}
+
 		var template string
-		var err error
+
+		isDirectory := file != "" && model.IsDirectoryTemplate(file)
 
 		if url != "" {
 			template, err = utils.ReadURL(url)
+		} else if isDirectory {
+			template, err = utils.ReadFile(filepath.Join(file, model.DirectoryTemplateFile))
 		} else if file != "" {
 			template, err = utils.ReadFile(file)
 		}
@@ -54,37 +96,187 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
-		tmpl, err := model.FromYAML(template)
+		var tmpl model.Template
+		if isDirectory {
+			tmpl, err = model.FromDirectory(file)
+		} else {
+			tmpl, err = model.FromYAML(template)
+		}
+		if err != nil {
+			return errors.New(yaml.FormatError(err, true, true))
+		}
+
+		source := file
+		if isDirectory {
+			source = filepath.Join(file, model.DirectoryTemplateFile)
+		} else if url != "" {
+			source = url
+			if !strings.HasPrefix(source, "http") {
+				source = "https://" + source
+			}
+		}
+
+		tmpl, err = parser.ResolveImports(tmpl, source)
 		if err != nil {
 			return err
 		}
 
-		tmpl, err = parser.ParseTemplate(tmpl)
+		if validationErrors := tmpl.Validate(); validationErrors != nil {
+			return parser.ValidationFailed(model.Locate(validationErrors, source, []byte(template)), true)
+		}
+
+		if !silent {
+			for _, name := range tmpl.UnusedVariables() {
+				pterm.Warning.Printfln("Variable %s is never used", name)
+			}
+		}
+
+		// Files can only be written to an output directory
+		if len(tmpl.Files) > 0 && outputDir == "" {
+			return fmt.Errorf("template generates files, use the output-dir flag to set an output directory")
+		}
+
+		vals := make(map[string]any)
+		if replay != "" {
+			answers, err := values.ReadFiles(replay)
+			if err != nil {
+				return err
+			}
+			vals = values.Known(tmpl, answers)
+		}
+
+		fileValues, err := values.ReadFiles(valueFiles...)
 		if err != nil {
 			return err
 		}
+		values.Merge(vals, fileValues)
 
-		result, err := parser.RenderTemplate(tmpl)
+		for _, assignment := range setValues {
+			if err := values.Set(vals, assignment); err != nil {
+				return err
+			}
+		}
+
+		for _, assignment := range setStringValues {
+			if err := values.SetString(vals, assignment); err != nil {
+				return err
+			}
+		}
+
+		tmpl, err = values.Apply(tmpl, vals)
+		if err != nil {
+			return parser.ValidationFailed(model.Locate(unwrapErrors(err), source, []byte(template)), true)
+		}
+
+		if noInput {
+			tmpl, err = parser.FillTemplate(tmpl, parser.WithEnvPrefix(envPrefix))
+			if err != nil {
+				return err
+			}
+		} else {
+			var defaults map[string]any
+			if !noHistory {
+				defaults, err = history.Load(source, template)
+				if err != nil {
+					pterm.Warning.Printfln("Could not load answer history: %s", err)
+				}
+			}
+
+			entered := make(map[string]any)
+			tmpl, err = parser.ParseTemplate(tmpl, parser.WithEnvPrefix(envPrefix), parser.WithDefaults(defaults), parser.WithEntered(entered))
+			if err != nil {
+				return err
+			}
+
+			// Only entered values are stored, values from the environment, values files or --set may be secrets
+			if !noHistory {
+				if defaults == nil {
+					defaults = make(map[string]any)
+				}
+				values.Merge(defaults, entered)
+
+				err := history.Save(source, template, defaults)
+				if err != nil {
+					pterm.Warning.Printfln("Could not save answer history: %s", err)
+				}
+			}
+		}
+
+		if record != "" {
+			err := values.WriteFile(record, values.Extract(tmpl), 0)
+			if err != nil {
+				return err
+			}
+		}
+
+		files, err := parser.RenderFiles(tmpl)
 		if err != nil {
 			return err
 		}
 
-		if output != "" {
-			err := os.WriteFile(output, []byte(result), 0644)
+		var result string
+		if tmpl.Template != "" {
+			result, err = parser.RenderTemplate(tmpl)
 			if err != nil {
 				return err
 			}
 		}
 
-		if clipboard {
-			clip.Write(clip.FmtText, []byte(result))
+		// Fail before writing any file, if any file or the output file conflicts
+		if conflictMode == writer.Fail {
+			for _, f := range files {
+				path := filepath.Join(outputDir, f.Path)
+				if w.Conflicts(path, []byte(f.Content)) {
+					return fmt.Errorf("file %s already exists", path)
+				}
+			}
+
+			if tmpl.Template != "" && output != "" && w.Conflicts(output, []byte(result)) {
+				return fmt.Errorf("file %s already exists", output)
+			}
+		}
+
+		for _, f := range files {
+			path := filepath.Join(outputDir, f.Path)
+			action, err := w.Write(path, []byte(f.Content), f.Mode)
+			if err != nil {
+				return err
+			}
+
+			if !silent {
+				printWriteAction(path, action, dryRun)
+				if dryRun && (action == writer.Created || action == writer.Overwritten) {
+					if f.Raw {
+						pterm.Println(pterm.Gray(fmt.Sprintf("<%d bytes copied verbatim>", len(f.Content))))
+					} else {
+						fmt.Println(f.Content)
+					}
+				}
+			}
 		}
 
-		if !silent {
-			fmt.Println()      // padding
-			fmt.Println("---") // padding
-			fmt.Println()      // padding
-			fmt.Println(result)
+		if tmpl.Template != "" {
+			if output != "" {
+				action, err := w.Write(output, []byte(result), 0)
+				if err != nil {
+					return err
+				}
+
+				if !silent {
+					printWriteAction(output, action, dryRun)
+				}
+			}
+
+			if clipboard {
+				clip.Write(clip.FmtText, []byte(result))
+			}
+
+			if !silent {
+				fmt.Println()      // padding
+				fmt.Println("---") // padding
+				fmt.Println()      // padding
+				fmt.Println(result)
+			}
 		}
 
 		if debug {
@@ -103,6 +295,31 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// printWriteAction prints what was done with an output file.
+func printWriteAction(path string, action writer.Action, dryRun bool) {
+	if dryRun {
+		messages := map[writer.Action]string{
+			writer.Created:     "Would create",
+			writer.Overwritten: "Would overwrite",
+			writer.Skipped:     "Would skip",
+			writer.Unchanged:   "Unchanged",
+		}
+		pterm.Info.Printfln("%s %s", messages[action], path)
+		return
+	}
+
+	switch action {
+	case writer.Created:
+		pterm.Success.Printfln("Created %s", path)
+	case writer.Overwritten:
+		pterm.Success.Printfln("Overwritten %s", path)
+	case writer.Skipped:
+		pterm.Info.Printfln("Skipped %s", path)
+	case writer.Unchanged:
+		pterm.Info.Printfln("Unchanged %s", path)
+	}
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// run executes the root command with the arguments.
+func run(t *testing.T, args ...string) {
+	t.Helper()
+
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	template := filepath.Join(dir, "template.yml")
+	answers := filepath.Join(dir, "answers.yml")
+	recorded := filepath.Join(dir, "recorded.txt")
+	replayed := filepath.Join(dir, "replayed.txt")
+
+	source := "variables:\n  - name: Name\n    type: text\n    default: Alice\n  - name: Due\n    type: date\n    default: +7d\ntemplate: '{{ .Name }} {{ .Due.Format \"2006-01-02\" }}'\n"
+	if err := os.WriteFile(template, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run(t, "-f", template, "--no-input", "--no-history", "-s", "--record", answers, "-o", recorded)
+
+	// Relative dates are recorded as the resulting date
+	content, err := os.ReadFile(answers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	if !strings.Contains(string(content), due) {
+		t.Fatalf("expected recorded date %s, got %q", due, content)
+	}
+
+	// Replayed values are used instead of the defaults
+	source = strings.ReplaceAll(source, "+7d", "+30d")
+	if err := os.WriteFile(template, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run(t, "-f", template, "--no-input", "--no-history", "-s", "--record", "", "--replay", answers, "-o", replayed)
+
+	expected, err := os.ReadFile(recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := os.ReadFile(replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != string(expected) || string(expected) != "Alice "+due {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
@@ -1,11 +1,17 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gttp-cli/gttp/pkg/form"
+	"github.com/gttp-cli/gttp/pkg/library"
 	"github.com/gttp-cli/gttp/pkg/model"
 	"github.com/gttp-cli/gttp/pkg/parser"
+	"github.com/gttp-cli/gttp/pkg/values"
+	"github.com/gttp-cli/gttp/pkg/web"
 	"github.com/spf13/cobra"
 	"strings"
 )
@@ -15,6 +21,7 @@ func init() {
 
 	// Add address flag
 	serveCmd.Flags().StringP("address", "a", "0.0.0.0:8080", "Address to listen on")
+	serveCmd.Flags().StringP("templates-dir", "t", "", "Directory of templates that can be rendered by name")
 }
 
 var serveCmd = &cobra.Command{
@@ -22,74 +29,407 @@ var serveCmd = &cobra.Command{
 	Short: "Start API server",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		addr, _ := cmd.Flags().GetString("address")
-		app := fiber.New()
+		templatesDir, _ := cmd.Flags().GetString("templates-dir")
 
-		app.Use(logger.New())
-		app.Use(cors.New())
+		var lib *library.Library
+		if templatesDir != "" {
+			var err error
+			lib, err = library.New(templatesDir)
+			if err != nil {
+				return err
+			}
+		}
+
+		return newServer(lib).Listen(addr)
+	},
+}
+
+// newServer creates the API server. Templates of the library can be rendered by name, if a library is given.
+func newServer(lib *library.Library) *fiber.App {
+	app := fiber.New()
+
+	app.Use(logger.New())
+	app.Use(cors.New())
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(map[string]string{
+			"status": "ok",
+			"docs":   "https://docs.gttp.dev",
+			"ui":     "/ui",
+		})
+	})
+
+	// /ui serves the built-in web form UI
+	app.Get("/ui", func(c *fiber.Ctx) error {
+		c.Type("html")
+		return c.Send(web.Index)
+	})
+
+	api := app.Group("/api")
+	v1 := api.Group("/v1")
+
+	// /parse accepts YAML and returns the parsed template as JSON
+	v1.Post("/parse", func(c *fiber.Ctx) error {
+		// Get template from JSON "template" key
+		body := struct {
+			Template string `json:"template"`
+		}{}
 
-		app.Get("/", func(c *fiber.Ctx) error {
-			return c.JSON(map[string]string{
-				"status": "ok",
-				"docs":   "https://docs.gttp.dev",
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
 			})
+		}
+
+		tmpl, err := decodeTemplate(body.Template)
+		if err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		// Validate template
+		errs := tmpl.Validate()
+		if errs != nil {
+			return c.Status(400).JSON(map[string]interface{}{
+				"errors": toValidationErrors(model.Locate(errs, "", []byte(body.Template))),
+			})
+		}
+
+		rendered, err := parser.RenderTemplate(tmpl)
+		if err != nil {
+			return c.Status(500).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		return c.JSON(map[string]string{
+			"template": body.Template,
+			"rendered": rendered,
 		})
+	})
 
-		api := app.Group("/api")
-		v1 := api.Group("/v1")
+	// /render accepts a template and values for its variables and returns the rendered template
+	v1.Post("/render", func(c *fiber.Ctx) error {
+		body := struct {
+			Template string         `json:"template"`
+			Values   map[string]any `json:"values"`
+		}{}
 
-		// /parse accepts YAML and returns the parsed template as JSON
-		v1.Post("/parse", func(c *fiber.Ctx) error {
-			// Get template from JSON "template" key
-			body := struct {
-				Template string `json:"template"`
-			}{}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
 
-			if err := c.BodyParser(&body); err != nil {
-				return c.Status(400).JSON(map[string]string{
-					"error": err.Error(),
-				})
-			}
+		tmpl, err := decodeTemplate(body.Template)
+		if err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
 
-			var tmpl model.Template
-			var err error
+		return render(c, tmpl, body.Template, body.Values)
+	})
 
-			if strings.HasPrefix(body.Template, "{") {
-				tmpl, err = model.FromJSON(body.Template)
-			} else {
-				tmpl, err = model.FromYAML(body.Template)
-			}
+	// /form accepts a template and returns a description of all its prompts, e.g. to build a web form
+	v1.Post("/form", func(c *fiber.Ctx) error {
+		body := struct {
+			Template string `json:"template"`
+		}{}
 
-			if err != nil {
-				return c.Status(400).JSON(map[string]string{
-					"error": err.Error(),
-				})
-			}
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
 
-			// Validate template
-			errs := tmpl.Validate()
-			if errs != nil {
-				var errors []string
-				for _, err := range errs {
-					errors = append(errors, err.Error())
-				}
-				return c.Status(400).JSON(map[string]interface{}{
-					"errors": errors,
-				})
-			}
+		tmpl, err := decodeTemplate(body.Template)
+		if err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
 
-			rendered, err := parser.RenderTemplate(tmpl)
-			if err != nil {
-				return c.Status(500).JSON(map[string]string{
-					"error": err.Error(),
-				})
+		return describeForm(c, tmpl, body.Template)
+	})
+
+	// /conditions accepts a template and values for its variables and returns which values with a condition are used
+	v1.Post("/conditions", func(c *fiber.Ctx) error {
+		body := struct {
+			Template string         `json:"template"`
+			Values   map[string]any `json:"values"`
+		}{}
+
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		tmpl, err := decodeTemplate(body.Template)
+		if err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		return conditions(c, tmpl, body.Values)
+	})
+
+	// /templates lists all templates of the templates directory
+	v1.Get("/templates", func(c *fiber.Ctx) error {
+		type templateInfo struct {
+			Name        string           `json:"name"`
+			Description string           `json:"description,omitempty"`
+			Variables   []model.Variable `json:"variables"`
+			Error       string           `json:"error,omitempty"`
+		}
+
+		templates := []templateInfo{}
+		if lib == nil {
+			return c.JSON(templates)
+		}
+
+		entries, err := lib.Templates()
+		if err != nil {
+			return c.Status(500).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		for _, entry := range entries {
+			info := templateInfo{
+				Name:        entry.Name,
+				Description: entry.Template.Description,
+				Variables:   entry.Template.Variables,
+			}
+			if entry.Error != nil {
+				info.Error = entry.Error.Error()
 			}
+			templates = append(templates, info)
+		}
+
+		return c.JSON(templates)
+	})
 
-			return c.JSON(map[string]string{
-				"template": body.Template,
-				"rendered": rendered,
+	// /templates/:name returns the content of a template of the templates directory
+	v1.Get("/templates/:name", func(c *fiber.Ctx) error {
+		entry, status, err := getLibraryTemplate(lib, c.Params("name"))
+		if err != nil {
+			return c.Status(status).JSON(map[string]string{
+				"error": err.Error(),
 			})
+		}
+
+		return c.JSON(map[string]string{
+			"name":     entry.Name,
+			"template": entry.Content,
 		})
+	})
 
-		return app.Listen(addr)
-	},
+	// /templates/:name/render renders a template of the templates directory with the given values
+	v1.Post("/templates/:name/render", func(c *fiber.Ctx) error {
+		entry, status, err := getLibraryTemplate(lib, c.Params("name"))
+		if err != nil {
+			return c.Status(status).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		body := struct {
+			Values map[string]any `json:"values"`
+		}{}
+
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		return render(c, entry.Template, entry.Content, body.Values)
+	})
+
+	// /templates/:name/form describes the prompts of a template of the templates directory, like /form
+	v1.Post("/templates/:name/form", func(c *fiber.Ctx) error {
+		entry, status, err := getLibraryTemplate(lib, c.Params("name"))
+		if err != nil {
+			return c.Status(status).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		return describeForm(c, entry.Template, entry.Content)
+	})
+
+	// /templates/:name/conditions evaluates the conditions of a template of the templates directory, like /conditions
+	v1.Post("/templates/:name/conditions", func(c *fiber.Ctx) error {
+		entry, status, err := getLibraryTemplate(lib, c.Params("name"))
+		if err != nil {
+			return c.Status(status).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		body := struct {
+			Values map[string]any `json:"values"`
+		}{}
+
+		if err := c.BodyParser(&body); err != nil {
+			return c.Status(400).JSON(map[string]string{
+				"error": err.Error(),
+			})
+		}
+
+		return conditions(c, entry.Template, body.Values)
+	})
+
+	return app
+}
+
+// render applies the values to the template and renders it.
+// Invalid or missing values are returned as validation errors, positioned in the source of the template.
+func render(c *fiber.Ctx, tmpl model.Template, source string, vals map[string]any) error {
+	// Errors in the definition of the template are reported together with unknown and invalid values
+	errs := tmpl.Validate()
+	tmpl, err := values.Apply(tmpl, vals)
+	if err != nil {
+		errs = append(errs, unwrapErrors(err)...)
+	}
+
+	if len(errs) > 0 {
+		return c.Status(400).JSON(map[string]any{
+			"errors": toValidationErrors(model.Locate(errs, "", []byte(source))),
+		})
+	}
+
+	// Templates are sent by clients, which must not read the environment of the server
+	tmpl, err = parser.FillTemplate(tmpl, parser.WithoutEnv())
+	if err != nil {
+		var missing parser.MissingValuesError
+		if errors.As(err, &missing) {
+			var errs []model.ValidationError
+			for _, name := range missing.Variables {
+				errs = append(errs, model.ValidationError{Variable: name, Path: name, Message: "value is required"})
+			}
+			return c.Status(400).JSON(map[string]any{
+				"errors": errs,
+			})
+		}
+
+		return c.Status(400).JSON(map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	rendered, err := parser.RenderTemplate(tmpl)
+	if err != nil {
+		return c.Status(500).JSON(map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(map[string]any{
+		"values":   values.Extract(tmpl),
+		"rendered": rendered,
+	})
+}
+
+// describeForm returns the description of all prompts of the template.
+// Errors in the template are returned as validation errors, positioned in the source of the template.
+func describeForm(c *fiber.Ctx, tmpl model.Template, source string) error {
+	errs := tmpl.Validate()
+	if errs != nil {
+		return c.Status(400).JSON(map[string]any{
+			"errors": toValidationErrors(model.Locate(errs, "", []byte(source))),
+		})
+	}
+
+	f, err := form.FromTemplate(tmpl)
+	if err != nil {
+		return c.Status(400).JSON(map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(f)
+}
+
+// conditions applies the values to the template and returns which values with a condition are used.
+func conditions(c *fiber.Ctx, tmpl model.Template, vals map[string]any) error {
+	// Unknown and invalid values are reported by render
+	tmpl, _ = values.Apply(tmpl, vals)
+
+	return c.JSON(map[string]any{
+		"visible": parser.Visibility(tmpl),
+	})
+}
+
+// getLibraryTemplate returns a valid template of the library.
+// If the template cannot be returned, the HTTP status code of the error is returned.
+func getLibraryTemplate(lib *library.Library, name string) (library.Entry, int, error) {
+	if lib == nil {
+		return library.Entry{}, 404, fmt.Errorf("no templates directory configured")
+	}
+
+	entry, ok, err := lib.Get(name)
+	if err != nil {
+		return library.Entry{}, 500, err
+	}
+
+	if !ok {
+		return library.Entry{}, 404, fmt.Errorf("template %s not found", name)
+	}
+
+	if entry.Error != nil {
+		return library.Entry{}, 500, fmt.Errorf("template %s is invalid: %w", name, entry.Error)
+	}
+
+	return entry, 200, nil
+}
+
+// decodeTemplate decodes a template from JSON or YAML.
+// Imports are not supported, as they could read files of the server.
+func decodeTemplate(template string) (model.Template, error) {
+	var tmpl model.Template
+	var err error
+
+	if strings.HasPrefix(template, "{") {
+		tmpl, err = model.FromJSON(template)
+	} else {
+		tmpl, err = model.FromYAML(template)
+	}
+	if err != nil {
+		return tmpl, err
+	}
+
+	if len(tmpl.Imports) > 0 {
+		return tmpl, fmt.Errorf("imports are only supported for templates of the templates directory")
+	}
+
+	return tmpl, nil
+}
+
+// unwrapErrors returns the errors that are joined in err.
+func unwrapErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+
+	return []error{err}
+}
+
+// toValidationErrors converts errors to validation errors.
+// Errors that do not belong to a variable only have a message.
+func toValidationErrors(errs []error) []model.ValidationError {
+	var validationErrors []model.ValidationError
+	for _, err := range errs {
+		var validationError model.ValidationError
+		if !errors.As(err, &validationError) {
+			validationError = model.ValidationError{Message: err.Error()}
+		}
+		validationErrors = append(validationErrors, validationError)
+	}
+
+	return validationErrors
 }
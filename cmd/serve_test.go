@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gttp-cli/gttp/pkg/library"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testTemplate = `variables:
+  - name: Name
+    type: text
+  - name: Age
+    type: number
+    max: 150
+  - name: Pager
+    type: text
+    condition: Age > 60
+template: "{{ .Name }} is {{ .Age }}"
+`
+
+// post sends the body as JSON to the server and returns the status code and the decoded response.
+func post(t *testing.T, app *fiber.App, path string, body any) (int, map[string]any) {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var response map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, response
+}
+
+// firstError returns the first error of a response with validation errors.
+func firstError(t *testing.T, response map[string]any) map[string]any {
+	t.Helper()
+
+	errs, ok := response["errors"].([]any)
+	if !ok || len(errs) == 0 {
+		t.Fatalf("expected errors, got %v", response)
+	}
+
+	return errs[0].(map[string]any)
+}
+
+func TestServeRender(t *testing.T) {
+	status, response := post(t, newServer(nil), "/api/v1/render", map[string]any{
+		"template": testTemplate,
+		"values":   map[string]any{"Name": "John", "Age": "42"},
+	})
+
+	if status != 200 {
+		t.Fatalf("expected status 200, got %d: %v", status, response)
+	}
+
+	if response["rendered"] != "John is 42" {
+		t.Fatalf("expected %q, got %v", "John is 42", response["rendered"])
+	}
+}
+
+func TestServeRenderUnknownVariable(t *testing.T) {
+	status, response := post(t, newServer(nil), "/api/v1/render", map[string]any{
+		"template": testTemplate,
+		"values":   map[string]any{"Name": "John", "Age": 42, "Email": "john@example.com"},
+	})
+
+	if status != 400 {
+		t.Fatalf("expected status 400, got %d: %v", status, response)
+	}
+
+	err := firstError(t, response)
+	if err["path"] != "Email" || err["message"] != "unknown variable" {
+		t.Fatalf("expected unknown variable Email, got %v", err)
+	}
+}
+
+func TestServeRenderInvalidValue(t *testing.T) {
+	status, response := post(t, newServer(nil), "/api/v1/render", map[string]any{
+		"template": testTemplate,
+		"values":   map[string]any{"Name": "John", "Age": 200},
+	})
+
+	if status != 400 {
+		t.Fatalf("expected status 400, got %d: %v", status, response)
+	}
+
+	err := firstError(t, response)
+	if err["path"] != "Age" || err["message"] != "value must be at most 150" {
+		t.Fatalf("expected invalid value of Age, got %v", err)
+	}
+
+	// Values are positioned at the definition of their variable
+	if err["line"] != float64(4) || err["column"] != float64(5) {
+		t.Fatalf("expected position 4:5, got %v:%v", err["line"], err["column"])
+	}
+}
+
+func TestServeRenderMalformedTemplate(t *testing.T) {
+	status, response := post(t, newServer(nil), "/api/v1/render", map[string]any{
+		"template": "variables: : :",
+	})
+
+	if status != 400 {
+		t.Fatalf("expected status 400, got %d: %v", status, response)
+	}
+
+	if _, ok := response["error"].(string); !ok {
+		t.Fatalf("expected error, got %v", response)
+	}
+}
+
+func TestServeRenderWithoutEnv(t *testing.T) {
+	t.Setenv("GTTP_TEST_SECRET", "hunter2")
+
+	status, response := post(t, newServer(nil), "/api/v1/render", map[string]any{
+		"template": "variables:\n  - name: X\n    type: text\n    env: GTTP_TEST_SECRET\ntemplate: '{{ .X }}'",
+	})
+
+	if status != 400 {
+		t.Fatalf("expected status 400, got %d: %v", status, response)
+	}
+
+	err := firstError(t, response)
+	if err["path"] != "X" || err["message"] != "value is required" {
+		t.Fatalf("expected missing value of X, got %v", err)
+	}
+}
+
+func TestServeForm(t *testing.T) {
+	status, response := post(t, newServer(nil), "/api/v1/form", map[string]any{
+		"template": testTemplate,
+	})
+
+	if status != 200 {
+		t.Fatalf("expected status 200, got %d: %v", status, response)
+	}
+
+	fields, ok := response["fields"].([]any)
+	if !ok || len(fields) != 3 {
+		t.Fatalf("expected 3 fields, got %v", response["fields"])
+	}
+
+	if pager := fields[2].(map[string]any); pager["name"] != "Pager" || pager["condition"] != "Age > 60" {
+		t.Fatalf("expected field Pager with condition, got %v", pager)
+	}
+}
+
+func TestServeConditions(t *testing.T) {
+	app := newServer(nil)
+
+	for age, expected := range map[int]bool{42: false, 70: true} {
+		status, response := post(t, app, "/api/v1/conditions", map[string]any{
+			"template": testTemplate,
+			"values":   map[string]any{"Age": age},
+		})
+
+		if status != 200 {
+			t.Fatalf("expected status 200, got %d: %v", status, response)
+		}
+
+		visible := response["visible"].(map[string]any)
+		if visible["Pager"] != expected {
+			t.Fatalf("age %d: expected Pager visible %v, got %v", age, expected, visible["Pager"])
+		}
+	}
+}
+
+func TestServeLibraryImports(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yml":   "imports:\n  - shared.yml\nvariables:\n  - name: User\n    type: person\n    condition: Env == \"prod\"\ntemplate: '{{ .Env }}{{ with .User }}: {{ .Name }}{{ end }}'\n",
+		"shared.yml": "structures:\n  person:\n    - name: Name\n      type: text\nvariables:\n  - name: Env\n    type: text\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lib, err := library.New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newServer(lib)
+
+	status, response := post(t, app, "/api/v1/templates/base/form", map[string]any{})
+	if status != 200 {
+		t.Fatalf("form: expected status 200, got %d: %v", status, response)
+	}
+	if fields := response["fields"].([]any); len(fields) != 3 {
+		t.Fatalf("form: expected the imported and own fields, got %v", fields)
+	}
+
+	vals := map[string]any{"Env": "prod", "User": map[string]any{"Name": "Ann"}}
+	status, response = post(t, app, "/api/v1/templates/base/conditions", map[string]any{"values": vals})
+	if status != 200 || response["visible"].(map[string]any)["User"] != true {
+		t.Fatalf("conditions: expected User to be visible, got %d: %v", status, response)
+	}
+
+	status, response = post(t, app, "/api/v1/templates/base/render", map[string]any{"values": vals})
+	if status != 200 || response["rendered"] != "prod: Ann" {
+		t.Fatalf("render: expected %q, got %d: %v", "prod: Ann", status, response)
+	}
+}
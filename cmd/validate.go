@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/gttp-cli/gttp/pkg/lint"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().String("format", string(lint.Text), "Output format: text, json, sarif or github")
+}
+
+var validateCmd = &cobra.Command{
+	Use:     "validate [files...]",
+	Aliases: []string{"lint"},
+	Short:   "Validate and lint templates",
+	Long: `Validate and lint template files, directory templates, directories and globs without running them.
+Exits with a non-zero exit code if any template has errors. Warnings do not fail.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("format")
+
+		format, err := lint.ParseFormat(name)
+		if err != nil {
+			return err
+		}
+
+		paths, err := lint.Expand(args...)
+		if err != nil {
+			return err
+		}
+
+		var issues []lint.Issue
+		for _, path := range paths {
+			issues = append(issues, lint.Check(path)...)
+		}
+
+		if err := lint.Write(os.Stdout, format, issues); err != nil {
+			return err
+		}
+
+		if errors, _ := lint.Count(issues); errors > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("validation of %d templates failed with %d errors", len(paths), errors)
+		}
+
+		return nil
+	},
+}
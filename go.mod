@@ -9,6 +9,7 @@ require (
 	github.com/gofiber/fiber/v2 v2.52.0
 	github.com/invopop/jsonschema v0.12.0
 	github.com/pterm/pterm v0.12.79
+	github.com/sergi/go-diff v1.3.1
 	github.com/spf13/cobra v1.8.0
 	golang.design/x/clipboard v0.7.0
 )
@@ -39,7 +40,6 @@ require (
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/mitchellh/reflectwalk v1.0.2 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
-	github.com/sergi/go-diff v1.3.1 // indirect
 	github.com/shopspring/decimal v1.3.1 // indirect
 	github.com/spf13/cast v1.6.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
@@ -56,6 +56,5 @@ require (
 	golang.org/x/term v0.17.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/xerrors v0.0.0-20231012003039-104605ab7028 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"github.com/gttp-cli/gttp/pkg/model"
+	"github.com/gttp-cli/gttp/pkg/parser"
 	"os"
 	"path/filepath"
 	"strings"
@@ -14,6 +15,19 @@ func TestTestFiles(t *testing.T) {
 	testPaths := []string{"testdata", "_examples"}
 	for _, testPath := range testPaths {
 		filepath.Walk(testPath, func(path string, info os.FileInfo, err error) error {
+			// Directory templates are tested as a whole
+			if info.IsDir() && isDirectoryTemplate(path) {
+				t.Run(path, func(t *testing.T) {
+					mustFail := strings.Contains(path, "must-fail")
+					if mustFail {
+						testFileMustFail(t, path)
+					} else {
+						testFileMustParse(t, path)
+					}
+				})
+				return filepath.SkipDir
+			}
+
 			if filepath.Ext(path) == ".yml" {
 				t.Run(path, func(t *testing.T) {
 					mustFail := strings.Contains(path, "must-fail")
@@ -44,18 +58,43 @@ func testFileMustFail(t *testing.T, path string) {
 	}
 }
 
+func isDirectoryTemplate(path string) bool {
+	_, err := os.Stat(filepath.Join(path, model.DirectoryTemplateFile))
+	return err == nil
+}
+
 func runTestFile(t *testing.T, path string) error {
-	// Read file
-	file, err := os.ReadFile(path)
-	if err != nil {
-		t.Fatal(err)
-		return nil
+	var template model.Template
+	if isDirectoryTemplate(path) {
+		var err error
+		template, err = model.FromDirectory(path)
+		if err != nil {
+			t.Fatal(err)
+			return nil
+		}
+	} else {
+		// Read file
+		file, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+			return nil
+		}
+
+		template, err = model.FromYAML(string(file))
+		if err != nil {
+			t.Fatal(err)
+			return nil
+		}
 	}
 
-	template, err := model.FromYAML(string(file))
+	source := path
+	if isDirectoryTemplate(path) {
+		source = filepath.Join(path, model.DirectoryTemplateFile)
+	}
+
+	template, err := parser.ResolveImports(template, source)
 	if err != nil {
-		t.Fatal(err)
-		return nil
+		return err
 	}
 
 	// Validate template
@@ -3,8 +3,13 @@ package main
 import (
 	"github.com/gttp-cli/gttp/cmd"
 	"github.com/pterm/pterm"
+	"os"
 )
 
 func main() {
-	pterm.Error.PrintOnError(cmd.Execute())
+	err := cmd.Execute()
+	pterm.Error.PrintOnError(err)
+	if err != nil {
+		os.Exit(1)
+	}
 }
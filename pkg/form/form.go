@@ -0,0 +1,216 @@
+package form
+
+import (
+	"fmt"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"github.com/gttp-cli/gttp/pkg/parser"
+	"strings"
+)
+
+// Form describes all prompts of a template, e.g. to render them as a web form.
+type Form struct {
+	// Fields are the fields of the form, in the order in which they are prompted.
+	// Fields of structures directly follow the field of their structure variable.
+	Fields []Field `json:"fields"`
+}
+
+// Field describes the prompt of a single variable or structure field.
+type Field struct {
+	// Name is the name of the variable or structure field.
+	Name string `json:"name"`
+	// Path is the full path of the field, e.g. "Admin.Name" or "Users[].Name" for fields of arrays.
+	Path string `json:"path"`
+	// Parent is the path of the structure variable the field belongs to.
+	// Empty for top-level variables.
+	Parent string `json:"parent,omitempty"`
+	// Label is the prompt that is shown when asking for the value.
+	Label string `json:"label"`
+	// Type is the resolved type of the field, without the array suffix.
+	// Fields of a structure type have the type "structure".
+	Type string `json:"type"`
+	// Structure is the name of the structure, if the type is "structure".
+	Structure string `json:"structure,omitempty"`
+	// Reference is the path of the field whose fields are used for the items of this field,
+	// if the field is an array of a structure that it is part of, e.g. "children: node[]".
+	// Such fields have no fields of their own.
+	Reference string `json:"reference,omitempty"`
+	// Array indicates if the field accepts multiple values.
+	Array bool `json:"array,omitempty"`
+	// Multiline indicates if the field is a multiline text.
+	Multiline bool `json:"multiline,omitempty"`
+	// Condition is the expr-lang expression that must be met for the field to be used.
+	Condition string `json:"condition,omitempty"`
+	// Default is the default value of the field.
+	// Defaults of select and multiselect fields are option values.
+	Default any `json:"default,omitempty"`
+	// Value is the predefined value of the field.
+	Value any `json:"value,omitempty"`
+	// Min is the minimum value of number, integer, date, datetime, time and duration fields.
+	Min any `json:"min,omitempty"`
+	// Max is the maximum value of number, integer, date, datetime, time and duration fields.
+	Max any `json:"max,omitempty"`
+	// MinLength is the minimum number of characters of text fields.
+	MinLength *int `json:"minLength,omitempty"`
+	// MaxLength is the maximum number of characters of text fields.
+	MaxLength *int `json:"maxLength,omitempty"`
+	// MinItems is the minimum number of items of arrays and multiselect fields.
+	MinItems *int `json:"minItems,omitempty"`
+	// MaxItems is the maximum number of items of arrays and multiselect fields.
+	MaxItems *int `json:"maxItems,omitempty"`
+	// Format is the layout of date, datetime and time fields.
+	Format string `json:"format,omitempty"`
+	// Regex is the regular expression that text values must match.
+	Regex string `json:"regex,omitempty"`
+	// ErrorMessage is the message shown for invalid values.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	// Options are the options of select and multiselect fields.
+	Options []Option `json:"options,omitempty"`
+}
+
+// Option is an option of a select or multiselect field.
+type Option struct {
+	// Name is the displayed name of the option.
+	Name string `json:"name"`
+	// Value is the resolved value of the option.
+	Value any `json:"value"`
+}
+
+// FromTemplate creates the form description of a template.
+func FromTemplate(template model.Template) (Form, error) {
+	var form Form
+	for _, variable := range template.Variables {
+		fields, err := describe(variable, "", "", template.Structures, nil)
+		if err != nil {
+			return Form{}, err
+		}
+		form.Fields = append(form.Fields, fields...)
+	}
+
+	return form, nil
+}
+
+// describe creates the fields of a variable.
+// The parent is the path of the structure variable the variable belongs to, the prefix is the path prefix of the variable.
+// Seen are the structure fields the variable is nested in.
+func describe(variable model.Variable, parent, prefix string, structures map[string][]model.Variable, seen []Field) ([]Field, error) {
+	if strings.HasSuffix(variable.Type, "[]") {
+		variable.IsArray = true
+		variable.Type = strings.TrimSuffix(variable.Type, "[]")
+	}
+
+	field := Field{
+		Name:      variable.Name,
+		Path:      variable.Name,
+		Parent:    parent,
+		Label:     parser.Prompt(variable, ""),
+		Type:      variable.Type,
+		Array:     variable.IsArray,
+		Multiline: variable.Multiline,
+		Condition: variable.Condition,
+		Default:   variable.Default,
+		Value:     variable.Value,
+		Min:       variable.Min,
+		Max:       variable.Max,
+		MinLength: variable.MinLength,
+		MaxLength: variable.MaxLength,
+		MinItems:  variable.MinItems,
+		MaxItems:  variable.MaxItems,
+		Format:    variable.Layout(),
+		Regex:     variable.Regex,
+
+		ErrorMessage: variable.ErrorMessage,
+	}
+
+	if prefix != "" {
+		field.Path = prefix + "." + variable.Name
+		field.Label = parser.Prompt(variable, strings.ReplaceAll(prefix, "[]", ""))
+	}
+
+	for _, option := range variable.Options {
+		field.Options = append(field.Options, Option{Name: option.Name, Value: option.ResolvedValue()})
+	}
+
+	switch variable.Type {
+	case "select":
+		field.Default = optionValue(variable.Options, variable.Default)
+	case "multiselect":
+		field.Default = optionValues(variable.Options, variable.Default)
+	}
+
+	structVars, ok := structures[variable.Type]
+	if !ok {
+		return []Field{field}, nil
+	}
+
+	field.Type = "structure"
+	field.Structure = variable.Type
+
+	for _, outer := range seen {
+		if outer.Structure != variable.Type {
+			continue
+		}
+		if !variable.IsArray {
+			return nil, fmt.Errorf("structure %s contains itself", variable.Type)
+		}
+
+		// Arrays can nest their own structure, reuse the fields of the outer field instead of describing them endlessly
+		field.Reference = outer.Path
+		return []Field{field}, nil
+	}
+
+	fieldPrefix := field.Path
+	if variable.IsArray {
+		fieldPrefix += "[]"
+	}
+
+	fields := []Field{field}
+	for _, structVar := range structVars {
+		structFields, err := describe(structVar, field.Path, fieldPrefix, structures, append(seen, field))
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, structFields...)
+	}
+
+	return fields, nil
+}
+
+// optionValue returns the value of the option with the given name.
+func optionValue(options []model.Option, name any) any {
+	if name == nil {
+		return nil
+	}
+
+	for _, option := range options {
+		if option.Name == fmt.Sprint(name) {
+			return option.ResolvedValue()
+		}
+	}
+
+	return name
+}
+
+// optionValues returns the values of the options with the given names.
+// Names can be a list or a string separated by ";".
+func optionValues(options []model.Option, names any) any {
+	var list []any
+	switch n := names.(type) {
+	case nil:
+		return nil
+	case []any:
+		list = n
+	case string:
+		for _, name := range strings.Split(n, ";") {
+			list = append(list, name)
+		}
+	default:
+		return names
+	}
+
+	values := make([]any, len(list))
+	for i, name := range list {
+		values[i] = optionValue(options, name)
+	}
+
+	return values
+}
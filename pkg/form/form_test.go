@@ -0,0 +1,51 @@
+package form
+
+import (
+	"github.com/gttp-cli/gttp/pkg/model"
+	"testing"
+)
+
+func TestFromTemplateNestedArray(t *testing.T) {
+	template := model.Template{
+		Structures: map[string][]model.Variable{
+			"node": {
+				{Name: "Name", Type: "text"},
+				{Name: "Children", Type: "node[]"},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Root", Type: "node"},
+		},
+	}
+
+	f, err := FromTemplate(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Fields) != 3 {
+		t.Fatalf("expected 3 fields, got %d", len(f.Fields))
+	}
+
+	children := f.Fields[2]
+	if children.Path != "Root.Children" || !children.Array || children.Structure != "node" || children.Reference != "Root" {
+		t.Fatalf("expected Root.Children to reference Root, got %+v", children)
+	}
+}
+
+func TestFromTemplateContainsItself(t *testing.T) {
+	template := model.Template{
+		Structures: map[string][]model.Variable{
+			"node": {
+				{Name: "Parent", Type: "node"},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Root", Type: "node"},
+		},
+	}
+
+	if _, err := FromTemplate(template); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
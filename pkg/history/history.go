@@ -0,0 +1,79 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gttp-cli/gttp/pkg/values"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the directory in which the answer history is stored.
+// It follows the XDG base directory specification and defaults to ~/.local/state/gttp/history.
+func Dir() (string, error) {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find state directory: %w", err)
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(stateDir, "gttp", "history"), nil
+}
+
+// Path returns the path of the history file of a template.
+// The file is keyed by the source of the template (file path or URL) and a hash of its content.
+func Path(source, content string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	if abs, err := filepath.Abs(source); err == nil && fileExists(source) {
+		source = abs
+	}
+
+	sourceHash := sha256.Sum256([]byte(source))
+	contentHash := sha256.Sum256([]byte(content))
+	name := fmt.Sprintf("%s-%s.yml", hex.EncodeToString(sourceHash[:8]), hex.EncodeToString(contentHash[:8]))
+
+	return filepath.Join(dir, name), nil
+}
+
+// Load returns the values that were last entered for a template.
+// If no values were stored yet, an empty map is returned.
+func Load(source, content string) (map[string]any, error) {
+	path, err := Path(source, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fileExists(path) {
+		return make(map[string]any), nil
+	}
+
+	return values.ReadFiles(path)
+}
+
+// Save stores the values that were entered for a template.
+// Entered values can be sensitive, so the history is only readable by the user.
+func Save(source, content string, vals map[string]any) error {
+	path, err := Path(source, content)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	return values.WriteFile(path, vals, 0600)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
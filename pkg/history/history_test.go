@@ -0,0 +1,58 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoad(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	err := Save("https://gttp.dev/demo.yml", "content", map[string]any{"Name": "John"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vals, err := Load("https://gttp.dev/demo.yml", "content")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if vals["Name"] != "John" {
+		t.Fatalf("expected Name to be John, got %v", vals["Name"])
+	}
+
+	vals, err = Load("https://gttp.dev/demo.yml", "changed content")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vals) != 0 {
+		t.Fatalf("expected no values for changed content, got %v", vals)
+	}
+}
+
+func TestSavePermission(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+
+	if err := Save("template.yml", "content", map[string]any{"Token": "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Path("template.yml", "content")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for file, expected := range map[string]os.FileMode{path: 0600, filepath.Dir(path): 0700} {
+		info, err := os.Stat(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != expected {
+			t.Fatalf("%s: expected permission %o, got %o", file, expected, info.Mode().Perm())
+		}
+	}
+}
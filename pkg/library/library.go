@@ -0,0 +1,169 @@
+package library
+
+import (
+	"fmt"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"github.com/gttp-cli/gttp/pkg/parser"
+	"github.com/gttp-cli/gttp/pkg/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Library is a directory of named templates.
+// Templates are reloaded when files in the directory or imported files are added, changed or removed.
+// Imports of templates are resolved when they are loaded.
+type Library struct {
+	dir string
+
+	mu        sync.Mutex
+	modTimes  map[string]time.Time
+	imports   []string
+	templates map[string]Entry
+}
+
+// Entry is a template of a library.
+type Entry struct {
+	// Name is the name of the template, which is the file name without extension.
+	Name string
+	// Content is the raw content of the template file.
+	Content string
+	// Template is the parsed template.
+	Template model.Template
+	// Error is set if the template file could not be parsed.
+	Error error
+}
+
+// New creates a library of all ".yml" and ".yaml" templates in the directory.
+func New(dir string) (*Library, error) {
+	l := &Library{dir: dir}
+	if err := l.refresh(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Templates returns all templates of the library, sorted by name.
+func (l *Library) Templates() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.refresh(); err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(l.templates))
+	for _, entry := range l.templates {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries, nil
+}
+
+// Get returns the template with the given name.
+func (l *Library) Get(name string) (Entry, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.refresh(); err != nil {
+		return Entry{}, false, err
+	}
+
+	entry, ok := l.templates[name]
+	return entry, ok, nil
+}
+
+// refresh reloads the templates if any template file or imported file was added, changed or removed since the last refresh.
+func (l *Library) refresh() error {
+	files, err := os.ReadDir(l.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	modTimes := make(map[string]time.Time)
+	for _, file := range files {
+		ext := filepath.Ext(file.Name())
+		if file.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return fmt.Errorf("failed to read template file %s: %w", file.Name(), err)
+		}
+		modTimes[file.Name()] = info.ModTime()
+	}
+
+	if l.templates != nil && !changed(l.modTimes, withImports(modTimes, l.imports)) {
+		return nil
+	}
+
+	templates := make(map[string]Entry, len(modTimes))
+	var imports []string
+	for file := range modTimes {
+		name := strings.TrimSuffix(file, filepath.Ext(file))
+		entry := Entry{Name: name}
+		path := filepath.Join(l.dir, file)
+		entry.Content, entry.Error = utils.ReadFile(path)
+		if entry.Error == nil {
+			entry.Template, entry.Error = model.FromYAML(entry.Content)
+		}
+		if entry.Error == nil {
+			var sources []string
+			entry.Template, sources, entry.Error = parser.ResolveImportSources(entry.Template, path)
+			imports = append(imports, sources...)
+		}
+		templates[name] = entry
+	}
+
+	l.modTimes = withImports(modTimes, imports)
+	l.imports = imports
+	l.templates = templates
+
+	return nil
+}
+
+// withImports returns the modification times of the template files together with the ones of the imported files, keyed by their path.
+// Imports from URLs are not tracked. Missing files have the zero time, so that they are tracked until they are added.
+func withImports(modTimes map[string]time.Time, imports []string) map[string]time.Time {
+	all := make(map[string]time.Time, len(modTimes)+len(imports))
+	for file, modTime := range modTimes {
+		all[file] = modTime
+	}
+
+	for _, file := range imports {
+		if strings.HasPrefix(file, "http://") || strings.HasPrefix(file, "https://") {
+			continue
+		}
+
+		var modTime time.Time
+		if info, err := os.Stat(file); err == nil {
+			modTime = info.ModTime()
+		}
+		all[file] = modTime
+	}
+
+	return all
+}
+
+func changed(old, new map[string]time.Time) bool {
+	if len(old) != len(new) {
+		return true
+	}
+
+	for file, modTime := range new {
+		if oldModTime, ok := old[file]; !ok || !oldModTime.Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,157 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile writes the file with a modification time that differs from the previous one,
+// as the modification times of files written in quick succession can be equal.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime().Add(time.Second)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "b.yaml"), "template: b")
+	writeFile(t, filepath.Join(dir, "a.yml"), "description: First\ntemplate: a")
+	writeFile(t, filepath.Join(dir, "invalid.yml"), "variables: 5")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a template")
+	if err := os.Mkdir(filepath.Join(dir, "sub.yml"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	lib, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := lib.Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "invalid" {
+		t.Fatalf("expected templates a, b and invalid, got %v", names)
+	}
+
+	if entries[0].Template.Description != "First" || entries[0].Content != "description: First\ntemplate: a" {
+		t.Fatalf("expected parsed template a, got %+v", entries[0])
+	}
+
+	if entries[2].Error == nil {
+		t.Fatal("expected error of invalid template, got nil")
+	}
+}
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "greeting.yml"), "template: Hello")
+
+	lib, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, ok, err := lib.Get("greeting")
+	if err != nil || !ok {
+		t.Fatalf("expected template greeting, got %v, %v", ok, err)
+	}
+	if entry.Template.Template != "Hello" {
+		t.Fatalf("expected template %q, got %q", "Hello", entry.Template.Template)
+	}
+
+	if _, ok, err := lib.Get("missing"); err != nil || ok {
+		t.Fatalf("expected no template, got %v, %v", ok, err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "greeting.yml")
+	writeFile(t, path, "template: Hello")
+
+	lib, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, path, "template: Hi")
+	writeFile(t, filepath.Join(dir, "farewell.yml"), "template: Bye")
+
+	entry, _, err := lib.Get("greeting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Template.Template != "Hi" {
+		t.Fatalf("expected changed template %q, got %q", "Hi", entry.Template.Template)
+	}
+
+	if _, ok, _ := lib.Get("farewell"); !ok {
+		t.Fatal("expected added template farewell")
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := lib.Get("greeting"); ok {
+		t.Fatal("expected removed template greeting to be gone")
+	}
+}
+
+func TestReloadImport(t *testing.T) {
+	dir := t.TempDir()
+	shared := filepath.Join(t.TempDir(), "shared.yml")
+	writeFile(t, shared, "variables:\n  - name: Name\n    type: text\n")
+	writeFile(t, filepath.Join(dir, "greeting.yml"), "imports:\n  - "+shared+"\ntemplate: 'Hello {{ .Name }}'")
+
+	lib, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, _, err := lib.Get("greeting")
+	if err != nil || entry.Error != nil {
+		t.Fatalf("expected template greeting, got %v, %v", err, entry.Error)
+	}
+	if len(entry.Template.Variables) != 1 || entry.Template.Variables[0].Type != "text" {
+		t.Fatalf("expected imported variable, got %+v", entry.Template.Variables)
+	}
+
+	writeFile(t, shared, "variables:\n  - name: Name\n    type: text\n  - name: Age\n    type: number\n")
+
+	entry, _, err = lib.Get("greeting")
+	if err != nil || entry.Error != nil {
+		t.Fatalf("expected template greeting, got %v, %v", err, entry.Error)
+	}
+	if len(entry.Template.Variables) != 2 {
+		t.Fatalf("expected changed imported variables, got %+v", entry.Template.Variables)
+	}
+
+	if err := os.Remove(shared); err != nil {
+		t.Fatal(err)
+	}
+
+	entry, _, err = lib.Get("greeting")
+	if err != nil || entry.Error == nil {
+		t.Fatalf("expected error of removed import, got %v, %v", err, entry.Error)
+	}
+}
@@ -0,0 +1,235 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an output format of issues.
+type Format string
+
+const (
+	// Text prints the issues for humans.
+	Text Format = "text"
+	// JSON prints the issues as JSON array.
+	JSON Format = "json"
+	// SARIF prints the issues as SARIF 2.1.0 log, e.g. for GitHub code scanning.
+	SARIF Format = "sarif"
+	// GitHub prints the issues as GitHub Actions workflow commands, which annotate the files.
+	GitHub Format = "github"
+)
+
+// Formats are all output formats.
+var Formats = []Format{Text, JSON, SARIF, GitHub}
+
+// ParseFormat parses the name of an output format.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == name {
+			return format, nil
+		}
+	}
+
+	var names []string
+	for _, format := range Formats {
+		names = append(names, string(format))
+	}
+	return "", fmt.Errorf("invalid format %s, must be one of: %s", name, strings.Join(names, ", "))
+}
+
+// Write writes the issues in the format.
+func Write(w io.Writer, format Format, issues []Issue) error {
+	switch format {
+	case JSON:
+		return writeJSON(w, issues)
+	case SARIF:
+		return writeSARIF(w, issues)
+	case GitHub:
+		return writeGitHub(w, issues)
+	default:
+		return writeText(w, issues)
+	}
+}
+
+// Count returns the number of errors and warnings.
+func Count(issues []Issue) (errors, warnings int) {
+	for _, issue := range issues {
+		if issue.Severity == Error {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// message returns the message of the issue, prefixed with the path of the variable.
+func (i Issue) message() string {
+	if i.Path != "" {
+		return fmt.Sprintf("variable %s: %s", i.Path, i.Message)
+	}
+	return i.Message
+}
+
+func writeText(w io.Writer, issues []Issue) error {
+	for _, issue := range issues {
+		position := issue.File
+		if issue.Line > 0 {
+			position = fmt.Sprintf("%s:%d:%d", issue.File, issue.Line, issue.Column)
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %s: %s (%s)\n", position, issue.Severity, issue.message(), issue.Rule); err != nil {
+			return err
+		}
+
+		if issue.Snippet != "" {
+			if _, err := fmt.Fprintf(w, "\n%s\n\n", issue.Snippet); err != nil {
+				return err
+			}
+		}
+	}
+
+	errors, warnings := Count(issues)
+	_, err := fmt.Fprintf(w, "%d %s, %d %s\n", errors, plural(errors, "error"), warnings, plural(warnings, "warning"))
+	return err
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+	return word + "s"
+}
+
+func writeJSON(w io.Writer, issues []Issue) error {
+	if issues == nil {
+		issues = []Issue{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+func writeSARIF(w io.Writer, issues []Issue) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "gttp",
+			InformationURI: "https://gttp.dev",
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	rules := make(map[string]bool)
+	for _, issue := range issues {
+		if !rules[issue.Rule] {
+			rules[issue.Rule] = true
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{ID: issue.Rule})
+		}
+
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(issue.File)},
+		}}
+		if issue.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: issue.Line, StartColumn: issue.Column}
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    issue.Rule,
+			Level:     string(issue.Severity),
+			Message:   sarifMessage{Text: issue.message()},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// writeGitHub writes workflow commands, see
+// https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions
+func writeGitHub(w io.Writer, issues []Issue) error {
+	for _, issue := range issues {
+		properties := []string{"file=" + escapeProperty(filepath.ToSlash(issue.File))}
+		if issue.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", issue.Line), fmt.Sprintf("col=%d", issue.Column))
+		}
+		properties = append(properties, "title="+escapeProperty(issue.Rule))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", issue.Severity, strings.Join(properties, ","), escapeData(issue.message())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,185 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"github.com/gttp-cli/gttp/pkg/parser"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Severity is the severity of an issue.
+type Severity string
+
+const (
+	// Error is an invalid definition. The template cannot be used.
+	Error Severity = "error"
+	// Warning is a valid definition that is likely a mistake.
+	Warning Severity = "warning"
+)
+
+// Rules of issues that are not found by model.Template.Lint.
+const (
+	RuleSyntax     = "syntax"
+	RuleImport     = "import"
+	RuleValidation = "validation"
+)
+
+// Issue is a problem found in a template.
+type Issue struct {
+	// File is the template file the issue was found in.
+	File string `json:"file"`
+	// Line is the line of the issue, starting at 1. It is 0 if the position is unknown.
+	Line int `json:"line,omitempty"`
+	// Column is the column of the issue, starting at 1. It is 0 if the position is unknown.
+	Column int `json:"column,omitempty"`
+	// Severity is the severity of the issue.
+	Severity Severity `json:"severity"`
+	// Rule is the rule that found the issue.
+	Rule string `json:"rule"`
+	// Variable is the name of the variable the issue belongs to, if any.
+	Variable string `json:"variable,omitempty"`
+	// Path is the path of the variable or structure field the issue belongs to, if any.
+	Path string `json:"path,omitempty"`
+	// Message describes the issue.
+	Message string `json:"message"`
+	// Snippet is the source around the issue, if the position is known.
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// Expand returns the template files and directory templates matching the patterns.
+// Patterns are files, directories or globs. Directories that are not directory templates
+// are searched for .yml and .yaml files and directory templates.
+func Expand(patterns ...string) ([]string, error) {
+	var paths []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			var err error
+			matches, err = filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %s", pattern)
+			}
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+
+			if !info.IsDir() || isDirectoryTemplate(match) {
+				add(match)
+				continue
+			}
+
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if d.IsDir() {
+					if isDirectoryTemplate(path) {
+						add(path)
+						return filepath.SkipDir
+					}
+					return nil
+				}
+
+				if ext := filepath.Ext(path); ext == ".yml" || ext == ".yaml" {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// Check loads the template file or directory template, resolves its imports,
+// and returns the validation errors and lint warnings of the template.
+func Check(path string) []Issue {
+	source := path
+	if isDirectoryTemplate(path) {
+		source = filepath.Join(path, model.DirectoryTemplateFile)
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return []Issue{{File: source, Severity: Error, Rule: RuleSyntax, Message: err.Error()}}
+	}
+
+	var tmpl model.Template
+	if source != path {
+		tmpl, err = model.FromDirectory(path)
+	} else {
+		tmpl, err = model.FromYAML(string(content))
+	}
+	if err != nil {
+		return []Issue{{File: source, Severity: Error, Rule: RuleSyntax, Message: yaml.FormatError(err, false, false)}}
+	}
+
+	tmpl, err = parser.ResolveImports(tmpl, source)
+	if err != nil {
+		return []Issue{{File: source, Severity: Error, Rule: RuleImport, Message: err.Error()}}
+	}
+
+	var issues []Issue
+	for _, err := range model.Locate(tmpl.Validate(), source, content) {
+		issues = append(issues, newIssue(source, Error, err))
+	}
+	for _, err := range model.Locate(tmpl.Lint(), source, content) {
+		issues = append(issues, newIssue(source, Warning, err))
+	}
+
+	return issues
+}
+
+// isDirectoryTemplate reports whether the directory contains the file of a directory template.
+func isDirectoryTemplate(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, model.DirectoryTemplateFile))
+	return err == nil && !info.IsDir()
+}
+
+func newIssue(file string, severity Severity, err error) Issue {
+	var validationError model.ValidationError
+	if !errors.As(err, &validationError) {
+		return Issue{File: file, Severity: severity, Rule: RuleValidation, Message: err.Error()}
+	}
+
+	issue := Issue{
+		File:     file,
+		Line:     validationError.Line,
+		Column:   validationError.Column,
+		Severity: severity,
+		Rule:     validationError.Rule,
+		Variable: validationError.Variable,
+		Path:     validationError.Path,
+		Message:  validationError.Message,
+		Snippet:  validationError.Snippet,
+	}
+	if issue.Rule == "" {
+		issue.Rule = RuleValidation
+	}
+
+	return issue
+}
@@ -0,0 +1,84 @@
+package lint
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "template.yml")
+	source := `variables:
+  - name: Env
+    type: select
+    default: staging
+    options:
+      - name: dev
+      - name: prod
+  - name: Unused
+    type: text
+  - name: Age
+    type: number
+    min: 10
+    max: 5
+template: "{{ .Env }} {{ .Age }}"
+`
+	if err := os.WriteFile(file, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := Check(file)
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %+v", issues)
+	}
+
+	if errors, warnings := Count(issues); errors != 1 || warnings != 2 {
+		t.Fatalf("expected 1 error and 2 warnings, got %d errors and %d warnings", errors, warnings)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, GitHub, issues); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "::error file=" + filepath.ToSlash(file) + ",line=12,col=5,title=validation::variable Age: min must not be greater than max\n" +
+		"::warning file=" + filepath.ToSlash(file) + ",line=8,col=5,title=unused-variable::variable Unused: never used\n" +
+		"::warning file=" + filepath.ToSlash(file) + ",line=4,col=5,title=default-not-in-options::variable Env: default staging is not in options\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestExpand(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"a.yml", "b.yaml", "c.txt", "sub/d.yml", "directory/gttp.yml", "directory/files/e.yml"} {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := Expand(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "a.yml"),
+		filepath.Join(dir, "b.yaml"),
+		filepath.Join(dir, "directory"),
+		filepath.Join(dir, "sub/d.yml"),
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %q, got %q", expected, paths)
+	}
+	for i := range expected {
+		if paths[i] != expected[i] {
+			t.Fatalf("expected %q, got %q", expected, paths)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/file"
+	"github.com/expr-lang/expr/parser"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RootVariable is the name under which conditions of structure fields can access the top-level variables.
+const RootVariable = "$root"
+
+// validateConditions compiles the conditions of all variables, structure fields and files.
+// Conditions of variables can only reference variables that are declared before them,
+// conditions of structure fields can only reference fields that are declared before them and the top-level variables as $root.
+func (t Template) validateConditions() []error {
+	var errs []error
+
+	all := make(map[string]any, len(t.Variables)+1)
+	for _, v := range t.Variables {
+		all[v.Name] = t.sampleValue(v, nil)
+	}
+	all[RootVariable] = copyEnv(all)
+
+	declared := map[string]any{RootVariable: all[RootVariable]}
+	for _, v := range t.Variables {
+		if v.Condition != "" {
+			if err := compileCondition(v.Condition, declared, all); err != nil {
+				errs = append(errs, newValidationError(v, "condition", err.Error()))
+			}
+		}
+		declared[v.Name] = all[v.Name]
+	}
+
+	for _, name := range sortedKeys(t.Structures) {
+		fields := t.Structures[name]
+
+		siblings := make(map[string]any, len(fields)+1)
+		for _, field := range fields {
+			siblings[field.Name] = t.sampleValue(field, nil)
+		}
+		siblings[RootVariable] = all[RootVariable]
+
+		declared := map[string]any{RootVariable: all[RootVariable]}
+		for _, field := range fields {
+			if field.Condition != "" {
+				if err := compileCondition(field.Condition, declared, siblings); err != nil {
+					errs = append(errs, ValidationError{
+						Variable: field.Name,
+						Path:     name + "." + field.Name,
+						Message:  err.Error(),
+						location: []string{"structures", name, "name=" + field.Name, "condition"},
+					})
+				}
+			}
+			declared[field.Name] = siblings[field.Name]
+		}
+	}
+
+	for i, f := range t.Files {
+		if f.Condition != "" {
+			if err := compileCondition(f.Condition, all, all); err != nil {
+				errs = append(errs, ValidationError{
+					Message:  fmt.Sprintf("file %d: %s", i+1, err),
+					location: []string{"files", fmt.Sprintf("#%d", i), "condition"},
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// compileCondition compiles the condition against the declared values.
+// If the condition only compiles against all values, the error names the variable that is declared too late.
+func compileCondition(condition string, declared, all map[string]any) error {
+	_, err := expr.Compile(condition, expr.Env(declared), expr.AsBool())
+	if err == nil {
+		return nil
+	}
+
+	if _, errAll := expr.Compile(condition, expr.Env(all), expr.AsBool()); errAll == nil {
+		for _, name := range identifiers(condition) {
+			if _, ok := declared[name]; !ok {
+				if _, ok := all[name]; ok {
+					return fmt.Errorf("condition %q references %s, which is declared later", condition, name)
+				}
+			}
+		}
+	}
+
+	message := err.Error()
+	var fileErr *file.Error
+	if errors.As(err, &fileErr) {
+		message = fileErr.Message
+	}
+
+	return fmt.Errorf("invalid condition %q: %s", condition, message)
+}
+
+// identifiers returns the names of all identifiers referenced by the condition.
+func identifiers(condition string) []string {
+	tree, err := parser.Parse(condition)
+	if err != nil {
+		return nil
+	}
+
+	var v identifierVisitor
+	ast.Walk(&tree.Node, &v)
+	return v.names
+}
+
+type identifierVisitor struct {
+	names []string
+}
+
+func (v *identifierVisitor) Visit(node *ast.Node) {
+	if n, ok := (*node).(*ast.IdentifierNode); ok {
+		v.names = append(v.names, n.Value)
+	}
+}
+
+// rootMembers returns the names of the top-level variables the condition accesses via $root, e.g. "Env" for "$root.Env".
+func rootMembers(condition string) []string {
+	tree, err := parser.Parse(condition)
+	if err != nil {
+		return nil
+	}
+
+	var v rootMemberVisitor
+	ast.Walk(&tree.Node, &v)
+	return v.names
+}
+
+type rootMemberVisitor struct {
+	names []string
+}
+
+func (v *rootMemberVisitor) Visit(node *ast.Node) {
+	n, ok := (*node).(*ast.MemberNode)
+	if !ok {
+		return
+	}
+
+	if root, ok := n.Node.(*ast.IdentifierNode); ok && root.Value == RootVariable {
+		if property, ok := n.Property.(*ast.StringNode); ok {
+			v.names = append(v.names, property.Value)
+		}
+	}
+}
+
+// sampleValue returns a zero value with the type the variable has at runtime,
+// so conditions can be type-checked. Unknown types are nil, which allows any usage.
+func (t Template) sampleValue(v Variable, seen map[string]bool) any {
+	if v.IsArray || strings.HasSuffix(v.Type, "[]") {
+		return []any{}
+	}
+
+	switch v.Type {
+	case "text":
+		return ""
+	case "number":
+		return float64(0)
+	case "integer":
+		return int64(0)
+	case "date", "datetime", "time":
+		return time.Time{}
+	case "duration":
+		return time.Duration(0)
+	case "boolean":
+		return false
+	case "select":
+		for _, o := range v.Options {
+			if _, ok := o.ResolvedValue().(string); !ok {
+				return nil
+			}
+		}
+		return ""
+	case "multiselect":
+		return []string{}
+	}
+
+	fields, ok := t.Structures[v.Type]
+	if !ok || seen[v.Type] {
+		return nil
+	}
+
+	nested := make(map[string]bool, len(seen)+1)
+	for name := range seen {
+		nested[name] = true
+	}
+	nested[v.Type] = true
+
+	value := make(map[string]any, len(fields))
+	for _, field := range fields {
+		value[field.Name] = t.sampleValue(field, nested)
+	}
+	return value
+}
+
+func sortedKeys(structures map[string][]Variable) []string {
+	names := make([]string, 0, len(structures))
+	for name := range structures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func copyEnv(env map[string]any) map[string]any {
+	c := make(map[string]any, len(env))
+	for name, value := range env {
+		c[name] = value
+	}
+	return c
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DirectoryTemplateFile is the name of the file that defines the variables and structures of a directory template.
+	DirectoryTemplateFile = "gttp.yml"
+	// DirectoryFilesDir is the name of the directory that contains the files of a directory template.
+	DirectoryFilesDir = "files"
+)
+
+// IsDirectoryTemplate checks if the path is a directory template.
+func IsDirectoryTemplate(dir string) bool {
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// FromDirectory reads a directory template.
+// A directory template consists of a "gttp.yml" file, which defines the variables and structures,
+// and a "files" directory, whose files are added to the files of the template.
+// Binary files are copied verbatim, all other files and all paths are parsed as Go text template.
+func FromDirectory(dir string) (Template, error) {
+	content, err := os.ReadFile(filepath.Join(dir, DirectoryTemplateFile))
+	if err != nil {
+		return Template{}, err
+	}
+
+	t, err := FromYAML(strings.ReplaceAll(string(content), "\r\n", "\n"))
+	if err != nil {
+		return Template{}, err
+	}
+
+	filesDir := filepath.Join(dir, DirectoryFilesDir)
+	if _, err := os.Stat(filesDir); os.IsNotExist(err) {
+		return t, nil
+	}
+
+	err = filepath.WalkDir(filesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(filesDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if rel == "." {
+			return nil
+		}
+
+		if t.isIgnored(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		t.Files = append(t.Files, File{
+			Path:    rel,
+			Content: string(content),
+			Raw:     isBinary(content),
+			Mode:    info.Mode().Perm(),
+		})
+
+		return nil
+	})
+	if err != nil {
+		return Template{}, fmt.Errorf("failed to read files of directory template: %w", err)
+	}
+
+	return t, nil
+}
+
+// isIgnored checks if the path matches any ignore pattern of the template.
+func (t Template) isIgnored(rel string) bool {
+	for _, pattern := range t.Ignore {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBinary checks if the content is binary, i.e. contains null bytes or is no valid UTF-8.
+func isBinary(content []byte) bool {
+	sample := content
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(content)
+}
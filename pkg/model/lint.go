@@ -0,0 +1,155 @@
+package model
+
+import (
+	"fmt"
+	"github.com/expr-lang/expr"
+	"strings"
+)
+
+// Lint rules reported by Template.Lint.
+const (
+	RuleUnusedVariable       = "unused-variable"
+	RuleUnreachableCondition = "unreachable-condition"
+	RuleDuplicateOptionValue = "duplicate-option-value"
+	RuleDefaultNotInOptions  = "default-not-in-options"
+)
+
+// Lint checks the template for definitions that are valid, but likely mistakes.
+// The returned errors are validation errors with the rule that found them.
+func (t Template) Lint() []error {
+	var errs []error
+
+	for _, name := range t.UnusedVariables() {
+		errs = append(errs, ValidationError{
+			Variable: name,
+			Path:     name,
+			Message:  "never used",
+			Rule:     RuleUnusedVariable,
+			location: []string{"variables", "name=" + name, "name"},
+		})
+	}
+
+	t.eachDefinition(func(v Variable, path string, location []string) {
+		if v.Condition != "" && unreachable(v.Condition) {
+			errs = append(errs, ValidationError{
+				Variable: v.Name,
+				Path:     path,
+				Message:  fmt.Sprintf("condition %q is never met", v.Condition),
+				Rule:     RuleUnreachableCondition,
+				location: append(location, "condition"),
+			})
+		}
+
+		seen := make(map[string]string, len(v.Options))
+		for _, o := range v.Options {
+			value := fmt.Sprint(o.ResolvedValue())
+			if other, ok := seen[value]; ok {
+				errs = append(errs, ValidationError{
+					Variable: v.Name,
+					Path:     path,
+					Message:  fmt.Sprintf("option %s has the same value as option %s", o.Name, other),
+					Rule:     RuleDuplicateOptionValue,
+					location: append(location, "options", "name="+o.Name),
+				})
+				continue
+			}
+			seen[value] = o.Name
+		}
+
+		// Defaults are the names or the values of options
+		var defaultValues []any
+		switch v.Type {
+		case "select":
+			if v.Default != nil {
+				defaultValues = []any{v.Default}
+			}
+		case "multiselect":
+			defaultValues = defaults(v.Default)
+		}
+		for _, d := range defaultValues {
+			if len(v.Options) > 0 && !inOptions(v.Options, d) {
+				errs = append(errs, ValidationError{
+					Variable: v.Name,
+					Path:     path,
+					Message:  fmt.Sprintf("default %v is not in options", d),
+					Rule:     RuleDefaultNotInOptions,
+					location: append(location, "default"),
+				})
+			}
+		}
+	})
+
+	for i, f := range t.Files {
+		if f.Condition != "" && unreachable(f.Condition) {
+			errs = append(errs, ValidationError{
+				Message:  fmt.Sprintf("file %d: condition %q is never met", i+1, f.Condition),
+				Rule:     RuleUnreachableCondition,
+				location: []string{"files", fmt.Sprintf("#%d", i), "condition"},
+			})
+		}
+	}
+
+	return errs
+}
+
+// eachDefinition calls fn for all variables and structure fields with their path and location in the template source.
+func (t Template) eachDefinition(fn func(v Variable, path string, location []string)) {
+	for _, v := range t.Variables {
+		fn(v, v.Name, []string{"variables", "name=" + v.Name})
+	}
+
+	for _, name := range sortedKeys(t.Structures) {
+		for _, field := range t.Structures[name] {
+			fn(field, name+"."+field.Name, []string{"structures", name, "name=" + field.Name})
+		}
+	}
+}
+
+// inOptions reports whether the value is the name or the value of one of the options.
+func inOptions(options []Option, value any) bool {
+	for _, o := range options {
+		if fmt.Sprint(value) == o.Name || fmt.Sprint(value) == fmt.Sprint(o.ResolvedValue()) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaults returns the default values of a multiselect variable.
+func defaults(value any) []any {
+	switch d := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return d
+	case string:
+		var values []any
+		for _, s := range strings.Split(d, ";") { // Same as the defaults offered when asking for input
+			values = append(values, s)
+		}
+		return values
+	case []string:
+		values := make([]any, len(d))
+		for i, s := range d {
+			values[i] = s
+		}
+		return values
+	default:
+		return []any{d}
+	}
+}
+
+// unreachable reports whether the condition does not depend on any variable and is never met, e.g. "1 > 2".
+func unreachable(condition string) bool {
+	if len(identifiers(condition)) > 0 {
+		return false
+	}
+
+	program, err := expr.Compile(condition)
+	if err != nil {
+		return false // Reported by Validate
+	}
+
+	result, err := expr.Run(program, nil)
+	return err == nil && result != true
+}
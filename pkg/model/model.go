@@ -3,9 +3,18 @@ package model
 import (
 	"encoding/json"
 	"github.com/goccy/go-yaml"
+	"io/fs"
 )
 
 type Template struct {
+	// Description describes what the template is used for.
+	Description string `json:"description,omitempty"`
+
+	// Imports are paths or URLs of other templates.
+	// Their structures, variables and named templates (defined with {{ define "name" }}) can be used in this template.
+	// Relative paths are resolved relative to the importing template.
+	Imports []string `json:"imports,omitempty"`
+
 	// Structures define custom types.
 	// They can be used as reusable components, consiting of multiple variables.
 	Structures map[string][]Variable `json:"structures,omitempty"`
@@ -15,13 +24,51 @@ type Template struct {
 	Variables []Variable `json:"variables"`
 
 	// Template defines the content of the template.
-	Template string `json:"template"`
+	// It is optional if the template defines files.
+	Template string `json:"template,omitempty"`
+
+	// Files define files that are generated from the template, e.g. to scaffold a project.
+	// They are written to the output directory.
+	Files []File `json:"files,omitempty"`
+
+	// Definitions are Go text templates that define named templates, e.g. the templates of imports.
+	// They are parsed together with the template content and the files, but never executed directly.
+	Definitions []string `json:"-"`
+
+	// Ignore are glob patterns of files in the "files" directory of a directory template that are not generated.
+	// Patterns are matched against the path relative to the "files" directory and against the file name.
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+type File struct {
+	// Path is the path of the file, relative to the output directory.
+	// The path can use the same Go text template syntax as the template content.
+	Path string `json:"path"`
+
+	// Content is the content of the file.
+	// The content can use the same Go text template syntax as the template content.
+	Content string `json:"content"`
+
+	// Condition is a condition that must be met for the file to be generated.
+	// Conditions are evaluated using expr-lang expressions (see: https://expr-lang.org/).
+	Condition string `json:"condition,omitempty"`
+
+	// Raw indicates that the content is copied verbatim, without parsing it as Go text template.
+	// Binary files of directory templates are always raw.
+	Raw bool `json:"raw,omitempty"`
+
+	// Mode is the permission of the file, e.g. 0755 for executable scripts. Defaults to 0644.
+	// Files of directory templates have the permission of their source file.
+	Mode fs.FileMode `json:"mode,omitempty"`
 }
 
 type Variable struct {
 	// Name is the name of the variable.
 	Name string `json:"name"`
 	// Type is the type of the variable.
+	// Built-in types are text, number, integer, boolean, select, multiselect, section,
+	// date, datetime, time and duration,
+	// other types are the names of structures.
 	Type string `json:"type"`
 	// IsArray indicates if the variable is an array.
 	// Can also be indicated by the type, e.g. "string[]".
@@ -36,6 +83,10 @@ type Variable struct {
 	// Conditions are evaluated using expr-lang expressions (see: https://expr-lang.org/).
 	Condition string `json:"condition,omitempty"`
 
+	// Env is the name of an environment variable that provides the value of the variable.
+	// If the environment variable is set, the user will not be asked for input.
+	Env string `json:"env,omitempty"`
+
 	// Value is the value of the variable.
 	// If the value is predefined in the template, the user will not be asked for input.
 	Value any `json:"value,omitempty"`
@@ -43,16 +94,39 @@ type Variable struct {
 	Default any `json:"default,omitempty"`
 
 	// Min is the minimum value of the variable.
-	// Only applicable to number types.
-	Min float64 `json:"min,omitempty"`
+	// Only applicable to number and integer types, and to date, datetime, time and duration types,
+	// whose bounds are given like their values, e.g. "2024-01-01", "now" or "+7d".
+	Min any `json:"min,omitempty" jsonschema:"oneof_type=number;string"`
 	// Max is the maximum value of the variable.
-	// Only applicable to number types.
-	Max float64 `json:"max,omitempty"`
+	// Only applicable to number and integer types, and to date, datetime, time and duration types,
+	// whose bounds are given like their values, e.g. "2024-01-01", "now" or "+7d".
+	Max any `json:"max,omitempty" jsonschema:"oneof_type=number;string"`
+
+	// MinLength is the minimum number of characters of the value.
+	// Only applicable to text types.
+	MinLength *int `json:"minLength,omitempty"`
+	// MaxLength is the maximum number of characters of the value.
+	// Only applicable to text types.
+	MaxLength *int `json:"maxLength,omitempty"`
+
+	// MinItems is the minimum number of items of the value.
+	// Only applicable to arrays and multiselect types.
+	MinItems *int `json:"minItems,omitempty"`
+	// MaxItems is the maximum number of items of the value.
+	// Only applicable to arrays and multiselect types.
+	MaxItems *int `json:"maxItems,omitempty"`
+
+	// Format is the layout of the values of date, datetime and time types, e.g. "02.01.2006".
+	// Layouts use the reference time of Go (see: https://pkg.go.dev/time#pkg-constants).
+	Format string `json:"format,omitempty"`
 
 	// Regex is a regular expression that the value must match.
 	// Only applicable to text types.
 	Regex string `json:"regex,omitempty"`
 
+	// ErrorMessage is shown instead of the generated error, if an entered value is invalid.
+	ErrorMessage string `json:"errorMessage,omitempty"`
+
 	// Options are the available options for select and multiselect types.
 	Options []Option `json:"options,omitempty"`
 }
@@ -67,6 +141,16 @@ type Option struct {
 	Value any `json:"value,omitempty"`
 }
 
+// ResolvedValue returns the value of the option.
+// If no value is provided, the name of the option is returned.
+func (o Option) ResolvedValue() any {
+	if o.Value != nil {
+		return o.Value
+	}
+
+	return o.Name
+}
+
 func (t Template) ToJSON() (string, error) {
 	j, err := json.MarshalIndent(t, "", "  ")
 	if err != nil {
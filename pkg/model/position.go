@@ -0,0 +1,126 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/printer"
+	"github.com/goccy/go-yaml/token"
+	"strings"
+)
+
+// Locate adds the file, line, column and a snippet of the source to the validation errors,
+// whose definition can be found in the YAML or JSON source of the template.
+// Definitions that are not part of the source, e.g. imported variables, are left without position.
+// Values that are not part of the source, e.g. values passed to the render API, are positioned at the definition of their variable.
+func Locate(errs []error, file string, source []byte) []error {
+	if len(errs) == 0 {
+		return errs
+	}
+
+	f, err := parser.ParseBytes(source, 0)
+	if err != nil || len(f.Docs) == 0 {
+		return errs
+	}
+
+	located := make([]error, 0, len(errs))
+	for _, err := range errs {
+		var validationError ValidationError
+		if !errors.As(err, &validationError) || validationError.location == nil {
+			located = append(located, err)
+			continue
+		}
+
+		tk := find(f.Docs[0].Body, validationError.location)
+		if tk == nil {
+			tk = find(f.Docs[0].Body, definitionOf(validationError.location))
+		}
+		if tk == nil {
+			located = append(located, err)
+			continue
+		}
+
+		validationError.File = file
+		validationError.Line = tk.Position.Line
+		validationError.Column = tk.Position.Column
+		validationError.Snippet = strings.TrimRight(new(printer.Printer).PrintErrorToken(tk, false), " \n")
+		validationError.token = tk
+		located = append(located, validationError)
+	}
+
+	return located
+}
+
+// Format returns the error followed by the highlighted source of the invalid definition, if known.
+func (e ValidationError) Format(colored bool) string {
+	if e.token == nil {
+		return e.Error()
+	}
+
+	return fmt.Sprintf("%s\n\n%s", e.Error(), new(printer.Printer).PrintErrorToken(e.token, colored))
+}
+
+// find returns the token of the node at the location.
+// Each step of the location is a mapping key, "name=<name>" for the item of a sequence with that name,
+// or "#<index>" for the item of a sequence at that index.
+// If the location cannot be followed completely, e.g. for imported definitions or values that are not part
+// of the source, nil is returned, as the tokens found so far belong to other definitions.
+func find(node ast.Node, location []string) *token.Token {
+	var tk *token.Token
+	for _, step := range location {
+		next, key := child(node, step)
+		if next == nil {
+			return nil
+		}
+
+		node = next
+		tk = key
+	}
+
+	return tk
+}
+
+// definitionOf returns the location of the definition the value at the location belongs to,
+// or nil if the location is not the location of a value.
+func definitionOf(location []string) []string {
+	for i, step := range location {
+		if step == "value" && i > 0 {
+			return location[:i]
+		}
+	}
+
+	return nil
+}
+
+// child returns the child of the node for a step of a location, and the token pointing at it.
+func child(node ast.Node, step string) (ast.Node, *token.Token) {
+	switch n := node.(type) {
+	case *ast.DocumentNode:
+		return child(n.Body, step)
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			if next, tk := child(value, step); next != nil {
+				return next, tk
+			}
+		}
+	case *ast.MappingValueNode:
+		if n.Key.GetToken().Value == step {
+			return n.Value, n.Key.GetToken()
+		}
+	case *ast.SequenceNode:
+		for i, item := range n.Values {
+			if step == fmt.Sprintf("#%d", i) {
+				return item, item.GetToken()
+			}
+
+			if name, ok := strings.CutPrefix(step, "name="); ok {
+				if value, tk := child(item, "name"); value != nil && value.GetToken().Value == name {
+					return item, tk
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	source := `variables:
+  - name: Name
+    type: text
+  - name: Age
+    type: number
+    min: 10
+    max: 5
+template: "{{ .Name }} {{ .Age }}"
+`
+
+	tmpl, err := FromYAML(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := Locate(tmpl.Validate(), "gttp.yml", []byte(source))
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	var validationError ValidationError
+	if !errors.As(errs[0], &validationError) {
+		t.Fatalf("expected validation error, got %T", errs[0])
+	}
+
+	if validationError.Line != 6 || validationError.Column != 5 {
+		t.Fatalf("expected position 6:5, got %d:%d", validationError.Line, validationError.Column)
+	}
+
+	expected := "gttp.yml:6:5: variable Age: min must not be greater than max"
+	if validationError.Error() != expected {
+		t.Fatalf("expected %q, got %q", expected, validationError.Error())
+	}
+}
+
+func TestLocateNotInSource(t *testing.T) {
+	source := `variables:
+  - import: other.yml
+  - name: Age
+    type: number
+    max: 5
+template: "{{ .Age }}"
+`
+
+	errs := []error{
+		ValidationError{Message: "variable Imported: invalid", location: []string{"variables", "name=Imported", "type"}},
+		ValidationError{Message: "variable Other: value must be at most 5", location: []string{"variables", "name=Other", "value"}},
+	}
+
+	for _, err := range Locate(errs, "gttp.yml", []byte(source)) {
+		var validationError ValidationError
+		if !errors.As(err, &validationError) {
+			t.Fatalf("expected validation error, got %T", err)
+		}
+
+		if validationError.Line != 0 || validationError.File != "" {
+			t.Fatalf("expected no position, got %q", validationError.Error())
+		}
+	}
+}
+
+func TestLocateValue(t *testing.T) {
+	source := `variables:
+  - name: Name
+    type: text
+  - name: Age
+    type: number
+    max: 5
+template: "{{ .Name }} {{ .Age }}"
+`
+
+	errs := []error{
+		ValidationError{Message: "variable Age: value must be at most 5", location: []string{"variables", "name=Age", "value"}},
+	}
+
+	var validationError ValidationError
+	if !errors.As(Locate(errs, "", []byte(source))[0], &validationError) {
+		t.Fatal("expected validation error")
+	}
+
+	if validationError.Line != 4 || validationError.Column != 5 {
+		t.Fatalf("expected position of the definition 4:5, got %d:%d", validationError.Line, validationError.Column)
+	}
+}
@@ -0,0 +1,316 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"text/template/parse"
+)
+
+// UnusedVariables returns the names of the variables that are neither referenced
+// by the template, the files nor the conditions of other variables and files.
+func (t Template) UnusedVariables() []string {
+	_, used := t.checkReferences()
+
+	var unused []string
+	for _, v := range t.Variables {
+		if v.Type != "section" && !used[v.Name] {
+			unused = append(unused, v.Name)
+		}
+	}
+	return unused
+}
+
+// checkReferences parses the template and the files and reports references to variables
+// and structure fields that are not declared. It also returns the names of the referenced variables.
+func (t Template) checkReferences() ([]error, map[string]bool) {
+	c := &referenceChecker{
+		template: t,
+		trees:    make(map[string]*parse.Tree),
+		used:     make(map[string]bool),
+		visiting: make(map[string]bool),
+	}
+
+	for i, definition := range t.Definitions {
+		c.parse(fmt.Sprintf("definition-%d", i), definition, nil)
+	}
+	definitions := c.trees
+
+	type rootTree struct {
+		tree     *parse.Tree
+		trees    map[string]*parse.Tree
+		location []string
+	}
+
+	// The template and the files are parsed separately, so each one can define the same templates
+	var roots []rootTree
+	parseRoot := func(name, text string, location []string) {
+		c.trees = copyTrees(definitions)
+		roots = append(roots, rootTree{c.parse(name, text, location), c.trees, location})
+	}
+
+	if t.Template != "" {
+		parseRoot("template", t.Template, []string{"template"})
+	}
+	for i, f := range t.Files {
+		parseRoot(f.Path+" (path)", f.Path, []string{"files", fmt.Sprintf("#%d", i), "path"})
+		if !f.Raw {
+			parseRoot(f.Path, f.Content, []string{"files", fmt.Sprintf("#%d", i), "content"})
+		}
+	}
+
+	root := &templateType{fields: t.Variables}
+	for _, r := range roots {
+		if r.tree != nil {
+			c.trees = r.trees
+			c.location = r.location
+			c.walk(r.tree, r.tree.Root, root, map[string]*templateType{"$": root})
+		}
+	}
+
+	for _, v := range t.Variables {
+		c.useCondition(v.Condition)
+	}
+	for _, fields := range t.Structures {
+		for _, field := range fields {
+			c.useCondition(field.Condition)
+		}
+	}
+	for _, f := range t.Files {
+		c.useCondition(f.Condition)
+	}
+
+	return c.errs, c.used
+}
+
+// templateType describes the value of dot or a template variable.
+// A nil type is unknown, e.g. a scalar or the result of a function, and is not checked.
+type templateType struct {
+	// name is the name of the structure, or empty for the top-level variables.
+	name string
+	// fields are the fields of a structure or the top-level variables.
+	fields []Variable
+	// elem is the type of the items if the value is an array.
+	elem *templateType
+	// array is true if the value is an array.
+	array bool
+}
+
+type referenceChecker struct {
+	template Template
+	trees    map[string]*parse.Tree
+	errs     []error
+	used     map[string]bool
+	visiting map[string]bool
+	// location is the location of the checked template in the template source.
+	location []string
+}
+
+func (c *referenceChecker) parse(name, text string, location []string) *parse.Tree {
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(text, "", "", c.trees); err != nil {
+		c.errs = append(c.errs, ValidationError{Message: err.Error(), location: location})
+		return nil
+	}
+	return tree
+}
+
+func (c *referenceChecker) errorf(tree *parse.Tree, node parse.Node, format string, args ...any) {
+	location, _ := tree.ErrorContext(node)
+	c.errs = append(c.errs, ValidationError{
+		Message:  location + ": " + fmt.Sprintf(format, args...),
+		location: c.location,
+	})
+}
+
+// typeOf returns the type of the value of the variable.
+func (c *referenceChecker) typeOf(v Variable) *templateType {
+	if v.IsArray || strings.HasSuffix(v.Type, "[]") {
+		v.IsArray = false
+		v.Type = strings.TrimSuffix(v.Type, "[]")
+		return &templateType{array: true, elem: c.typeOf(v)}
+	}
+
+	if fields, ok := c.template.Structures[v.Type]; ok {
+		return &templateType{name: v.Type, fields: fields}
+	}
+	return nil
+}
+
+func (c *referenceChecker) walk(tree *parse.Tree, node parse.Node, dot *templateType, vars map[string]*templateType) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(tree, child, dot, vars)
+		}
+	case *parse.ActionNode:
+		c.pipe(tree, n.Pipe, dot, vars)
+	case *parse.IfNode:
+		inner := copyVars(vars)
+		c.pipe(tree, n.Pipe, dot, inner)
+		c.walk(tree, n.List, dot, inner)
+		c.walk(tree, n.ElseList, dot, copyVars(vars))
+	case *parse.WithNode:
+		inner := copyVars(vars)
+		typ := c.pipe(tree, n.Pipe, dot, inner)
+		c.walk(tree, n.List, typ, inner)
+		c.walk(tree, n.ElseList, dot, copyVars(vars))
+	case *parse.RangeNode:
+		inner := copyVars(vars)
+		typ := c.pipe(tree, n.Pipe, dot, inner)
+
+		var elem *templateType
+		if typ != nil && typ.array {
+			elem = typ.elem
+		}
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			inner[n.Pipe.Decl[0].Ident[0]] = nil
+			inner[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+
+		c.walk(tree, n.List, elem, inner)
+		c.walk(tree, n.ElseList, dot, copyVars(vars))
+	case *parse.TemplateNode:
+		var typ *templateType
+		if n.Pipe != nil {
+			typ = c.pipe(tree, n.Pipe, dot, vars)
+		}
+
+		// Follow the invoked template, unless it is already being checked
+		called, ok := c.trees[n.Name]
+		if !ok || c.visiting[n.Name] {
+			return
+		}
+		c.visiting[n.Name] = true
+		c.walk(called, called.Root, typ, map[string]*templateType{"$": typ})
+		c.visiting[n.Name] = false
+	}
+}
+
+// pipe checks the references of a pipeline and returns the type of its result.
+// Variables declared by the pipeline are added to vars.
+func (c *referenceChecker) pipe(tree *parse.Tree, pipe *parse.PipeNode, dot *templateType, vars map[string]*templateType) *templateType {
+	if pipe == nil {
+		return nil
+	}
+
+	var typ *templateType
+	for _, cmd := range pipe.Cmds {
+		typ = nil
+		for _, arg := range cmd.Args {
+			typ = c.arg(tree, arg, dot, vars)
+		}
+		if len(cmd.Args) != 1 {
+			typ = nil // Result of a function call
+		}
+	}
+
+	if len(pipe.Cmds) != 1 {
+		typ = nil
+	}
+
+	for _, decl := range pipe.Decl {
+		vars[decl.Ident[0]] = typ
+	}
+
+	return typ
+}
+
+func (c *referenceChecker) arg(tree *parse.Tree, arg parse.Node, dot *templateType, vars map[string]*templateType) *templateType {
+	switch n := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return c.resolve(tree, n, dot, n.Ident, "")
+	case *parse.VariableNode:
+		typ, ok := vars[n.Ident[0]]
+		if !ok {
+			return nil
+		}
+		return c.resolve(tree, n, typ, n.Ident[1:], n.Ident[0])
+	case *parse.PipeNode:
+		return c.pipe(tree, n, dot, copyVars(vars))
+	case *parse.ChainNode:
+		c.arg(tree, n.Node, dot, vars)
+	}
+	return nil
+}
+
+// resolve follows the field names starting at the given type and reports fields that do not exist.
+func (c *referenceChecker) resolve(tree *parse.Tree, node parse.Node, typ *templateType, idents []string, prefix string) *templateType {
+	path := prefix
+	for _, ident := range idents {
+		path += "." + ident
+
+		if typ == nil {
+			return nil
+		}
+
+		if typ.array {
+			c.errorf(tree, node, "%s is an array, use range to access its items", strings.TrimSuffix(path, "."+ident))
+			return nil
+		}
+
+		var field *Variable
+		for i := range typ.fields {
+			if typ.fields[i].Name == ident {
+				field = &typ.fields[i]
+				break
+			}
+		}
+
+		if field == nil {
+			if typ.name == "" {
+				c.errorf(tree, node, "unknown variable %s", path)
+			} else {
+				c.errorf(tree, node, "unknown field %s of structure %s in %s", ident, typ.name, path)
+			}
+			return nil
+		}
+
+		if typ.name == "" {
+			c.used[field.Name] = true
+		}
+
+		typ = c.typeOf(*field)
+	}
+	return typ
+}
+
+// useCondition marks the variables referenced by the condition as used.
+func (c *referenceChecker) useCondition(condition string) {
+	if condition == "" {
+		return
+	}
+
+	for _, name := range identifiers(condition) {
+		c.used[name] = true
+	}
+
+	// Top-level variables referenced via $root, e.g. "$root.Env"
+	for _, name := range rootMembers(condition) {
+		c.used[name] = true
+	}
+}
+
+func copyTrees(trees map[string]*parse.Tree) map[string]*parse.Tree {
+	c := make(map[string]*parse.Tree, len(trees))
+	for name, tree := range trees {
+		c[name] = tree
+	}
+	return c
+}
+
+func copyVars(vars map[string]*templateType) map[string]*templateType {
+	c := make(map[string]*templateType, len(vars))
+	for name, typ := range vars {
+		c[name] = typ
+	}
+	return c
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnusedVariables(t *testing.T) {
+	tmpl := Template{
+		Structures: map[string][]Variable{
+			"user": {
+				{Name: "Name", Type: "text"},
+				{Name: "Pager", Type: "text", Condition: `$root.Env == "prod"`},
+			},
+		},
+		Variables: []Variable{
+			{Name: "Section", Type: "section"},
+			{Name: "Env", Type: "text"},
+			{Name: "AddUser", Type: "boolean"},
+			{Name: "Users", Type: "user[]", Condition: "AddUser"},
+			{Name: "Unused", Type: "text"},
+		},
+		Template: `{{ range .Users }}{{ .Name }}{{ end }}`,
+	}
+
+	unused := tmpl.UnusedVariables()
+	if expected := []string{"Unused"}; !reflect.DeepEqual(unused, expected) {
+		t.Fatalf("expected %q, got %q", expected, unused)
+	}
+}
+
+func TestUnusedVariablesRootPrefix(t *testing.T) {
+	tmpl := Template{
+		Structures: map[string][]Variable{
+			"user": {
+				{Name: "Greeting", Type: "text", Condition: `$root.Names != ""`},
+			},
+		},
+		Variables: []Variable{
+			{Name: "Name", Type: "text"},
+			{Name: "Names", Type: "text"},
+			{Name: "User", Type: "user"},
+		},
+		Template: `{{ .User.Greeting }}`,
+	}
+
+	unused := tmpl.UnusedVariables()
+	if expected := []string{"Name"}; !reflect.DeepEqual(unused, expected) {
+		t.Fatalf("expected %q, got %q", expected, unused)
+	}
+}
+
+func TestCheckReferencesDefineInFiles(t *testing.T) {
+	tmpl := Template{
+		Variables: []Variable{
+			{Name: "Name", Type: "text"},
+		},
+		Template: `{{ define "title" }}{{ .Name }}{{ end }}{{ template "title" . }}`,
+		Files: []File{
+			{Path: "a.md", Content: `{{ define "title" }}A {{ .Name }}{{ end }}{{ template "title" . }}`},
+			{Path: "b.md", Content: `{{ define "title" }}B {{ .Unknown }}{{ end }}{{ template "title" . }}`},
+		},
+	}
+
+	errs, _ := tmpl.checkReferences()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "unknown variable .Unknown") {
+		t.Fatalf("expected only the unknown variable in b.md, got %v", errs)
+	}
+}
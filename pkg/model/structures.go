@@ -0,0 +1,301 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BuiltinTypes are the types of variables that are not structures.
+var BuiltinTypes = []string{"text", "number", "integer", "boolean", "select", "multiselect", "section", "date", "datetime", "time", "duration"}
+
+// validateTypes checks that the types of all variables and structure fields are built-in types or structures.
+func (t Template) validateTypes() []error {
+	var errs []error
+	t.eachDefinition(func(v Variable, path string, location []string) {
+		name := strings.TrimSuffix(v.Type, "[]")
+		if name == "" || t.isBuiltinType(name) {
+			return
+		}
+
+		if _, ok := t.Structures[name]; !ok {
+			errs = append(errs, ValidationError{
+				Variable: v.Name,
+				Path:     path,
+				Message:  fmt.Sprintf("unknown type %s", name),
+				location: append(location, "type"),
+			})
+		}
+	})
+	return errs
+}
+
+func (t Template) isBuiltinType(name string) bool {
+	for _, builtin := range BuiltinTypes {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+// validateStructures validates the fields of all structures with the same rules as variables,
+// and checks that no structure contains itself, which would be asked for endlessly.
+func (t Template) validateStructures() []error {
+	var errs []error
+	for _, name := range sortedKeys(t.Structures) {
+		if t.isBuiltinType(name) {
+			errs = append(errs, ValidationError{
+				Message:  fmt.Sprintf("structure %s: name is a built-in type", name),
+				location: []string{"structures", name},
+			})
+		}
+
+		for _, field := range t.Structures[name] {
+			for _, err := range field.Validate() {
+				errs = append(errs, inStructure(err, name, field))
+			}
+		}
+
+		if t.containsItself(name, name, map[string]bool{}) {
+			errs = append(errs, ValidationError{
+				Message:  fmt.Sprintf("structure %s contains itself, use an array to nest it", name),
+				location: []string{"structures", name},
+			})
+		}
+	}
+	return errs
+}
+
+// containsItself reports whether the structure contains the target structure through fields that are not arrays.
+func (t Template) containsItself(structure, target string, seen map[string]bool) bool {
+	if seen[structure] {
+		return false
+	}
+	seen[structure] = true
+
+	for _, field := range t.Structures[structure] {
+		if field.IsArray || strings.HasSuffix(field.Type, "[]") {
+			continue
+		}
+		if field.Type == target {
+			return true
+		}
+		if _, ok := t.Structures[field.Type]; ok && t.containsItself(field.Type, target, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// inStructure qualifies a validation error of a structure field with the name of the structure.
+func inStructure(err error, structure string, field Variable) error {
+	validationError, ok := err.(ValidationError)
+	if !ok {
+		return err
+	}
+
+	validationError.Path = structure + "." + field.Name
+	key := validationError.location[len(validationError.location)-1]
+	validationError.location = []string{"structures", structure, "name=" + field.Name, key}
+	return validationError
+}
+
+// ValidateValue validates the value of the variable.
+// The items of arrays and the fields of structures are validated recursively,
+// errors contain the path of the invalid value, e.g. "Users[1].Admin".
+func (t Template) ValidateValue(v Variable) []error {
+	return t.validateValue(v, v.Value, v.Name, v.Name, []string{"variables", "name=" + v.Name, "value"})
+}
+
+// validateValue validates a value of the variable with the given name. The location points at the value in the template source.
+func (t Template) validateValue(v Variable, value any, name, path string, location []string) []error {
+	if value == nil {
+		return nil
+	}
+
+	newError := func(message string) error {
+		return ValidationError{
+			Variable: name,
+			Path:     path,
+			Message:  message,
+			location: location,
+		}
+	}
+
+	if v.IsArray || strings.HasSuffix(v.Type, "[]") {
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice {
+			return []error{newError(fmt.Sprintf("value must be an array, got %T", value))}
+		}
+
+		item := v
+		item.IsArray = false
+		item.Type = strings.TrimSuffix(v.Type, "[]")
+		item.MinItems, item.MaxItems = nil, nil
+
+		var errs []error
+		for _, err := range validateItems(v, items.Len()) {
+			validationError := err.(ValidationError)
+			validationError.Variable = name
+			validationError.Path = path
+			validationError.location = location
+			errs = append(errs, validationError)
+		}
+
+		for i := 0; i < items.Len(); i++ {
+			itemLocation := append(append([]string(nil), location...), fmt.Sprintf("#%d", i))
+			errs = append(errs, t.validateValue(item, items.Index(i).Interface(), name, fmt.Sprintf("%s[%d]", path, i), itemLocation)...)
+		}
+		return errs
+	}
+
+	if fields, ok := t.Structures[v.Type]; ok {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return []error{newError(fmt.Sprintf("value must be a structure, got %T", value))}
+		}
+
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var errs []error
+		for _, key := range keys {
+			keyLocation := append(append([]string(nil), location...), key)
+
+			field, ok := findField(fields, key)
+			if !ok {
+				errs = append(errs, ValidationError{
+					Variable: name,
+					Path:     path + "." + key,
+					Message:  fmt.Sprintf("unknown field of structure %s", v.Type),
+					location: keyLocation,
+				})
+				continue
+			}
+			errs = append(errs, t.validateValue(field, m[key], name, path+"."+key, keyLocation)...)
+		}
+		return errs
+	}
+
+	// Single values are validated like variables, only errors of the value are reported
+	v.Value = value
+	var errs []error
+	for _, err := range v.ValidateValue() {
+		validationError := err.(ValidationError)
+		validationError.Variable = name
+		validationError.Path = path
+		validationError.location = location
+		errs = append(errs, validationError)
+	}
+	return errs
+}
+
+// TypedValue returns the value of the variable as it is passed to the template.
+// Integers are converted to int64, dates and times to time.Time and durations to time.Duration,
+// also in the items of arrays and the fields of structures.
+func (t Template) TypedValue(v Variable) any {
+	return t.typedValue(v, v.Value)
+}
+
+func (t Template) typedValue(v Variable, value any) any {
+	if value == nil {
+		return nil
+	}
+
+	if v.IsArray || strings.HasSuffix(v.Type, "[]") {
+		items, ok := value.([]any)
+		if !ok {
+			return value
+		}
+
+		item := v
+		item.IsArray = false
+		item.Type = strings.TrimSuffix(v.Type, "[]")
+
+		typed := make([]any, len(items))
+		for i := range items {
+			typed[i] = t.typedValue(item, items[i])
+		}
+		return typed
+	}
+
+	if fields, ok := t.Structures[v.Type]; ok {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return value
+		}
+
+		typed := make(map[string]any, len(m))
+		for key := range m {
+			typed[key] = m[key]
+			if field, ok := findField(fields, key); ok {
+				typed[key] = t.typedValue(field, m[key])
+			}
+		}
+		return typed
+	}
+
+	switch v.Type {
+	case "integer":
+		if integer, ok := asInteger(value); ok {
+			return integer
+		}
+	case "date", "datetime", "time", "duration":
+		if typed, err := v.parseTimeValue(value, time.Now()); err == nil {
+			return typed
+		}
+	}
+
+	return value
+}
+
+func findField(fields []Variable, name string) (Variable, bool) {
+	for _, field := range fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return Variable{}, false
+}
+
+// asFloat converts numbers of any kind to float64, e.g. integers decoded from YAML.
+func asFloat(value any) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// asInteger converts integers and floats without fraction to int64.
+func asInteger(value any) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
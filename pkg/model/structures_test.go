@@ -0,0 +1,40 @@
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateValue(t *testing.T) {
+	tmpl := Template{
+		Structures: map[string][]Variable{
+			"person": {
+				{Name: "Name", Type: "text"},
+				{Name: "Pets", Type: "text[]"},
+			},
+		},
+	}
+
+	v := Variable{
+		Name: "Users",
+		Type: "person[]",
+		Value: []any{
+			map[string]any{"Name": "Marvin", "Pets": []any{"cat"}},
+			map[string]any{"Name": "Test", "Pets": []any{"dog", 42}},
+		},
+	}
+
+	errs := tmpl.ValidateValue(v)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+
+	var validationError ValidationError
+	if !errors.As(errs[0], &validationError) {
+		t.Fatalf("expected validation error, got %T", errs[0])
+	}
+
+	if validationError.Variable != "Users" || validationError.Path != "Users[1].Pets[1]" {
+		t.Fatalf("expected error of Users[1].Pets[1], got %s", validationError)
+	}
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFormats are the layouts of date and time types without a format.
+// Layouts use the reference time of Go, see https://pkg.go.dev/time#pkg-constants.
+var DefaultFormats = map[string]string{
+	"date":     "2006-01-02",
+	"datetime": "2006-01-02 15:04",
+	"time":     "15:04",
+}
+
+// isTimeType reports whether the type is a point in time, i.e. date, datetime or time.
+func isTimeType(typ string) bool {
+	_, ok := DefaultFormats[typ]
+	return ok
+}
+
+// Layout returns the layout of date, datetime and time variables.
+func (v Variable) Layout() string {
+	if v.Format != "" {
+		return v.Format
+	}
+	return DefaultFormats[strings.TrimSuffix(v.Type, "[]")]
+}
+
+// ParseTime parses a value of a date, datetime or time variable.
+// Besides values in the layout of the variable, "now" and offsets to now like "+7d" or "-2h" are accepted.
+func (v Variable) ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "now" {
+		return v.truncate(now), nil
+	}
+
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		if offset, err := ParseDuration(value); err == nil {
+			return v.truncate(now.Add(offset)), nil
+		}
+	}
+
+	t, err := time.ParseInLocation(v.Layout(), value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s is not a %s in the format %s", value, v.Type, v.Layout())
+	}
+	return t, nil
+}
+
+// truncate drops the parts of the time that are not part of the type,
+// so that relative values can be compared with parsed values, e.g. the time of day of dates.
+func (v Variable) truncate(t time.Time) time.Time {
+	switch strings.TrimSuffix(v.Type, "[]") {
+	case "date":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case "time":
+		return time.Date(0, 1, 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	}
+	return t
+}
+
+// durationUnit matches days and weeks, which are not supported by time.ParseDuration.
+var durationUnit = regexp.MustCompile(`(\d+(?:\.\d+)?)([dw])`)
+
+// ParseDuration parses a duration like time.ParseDuration, but also accepts days (d) and weeks (w), e.g. "7d" or "1w2d".
+func ParseDuration(value string) (time.Duration, error) {
+	s := durationUnit.ReplaceAllStringFunc(strings.TrimSpace(value), func(match string) string {
+		parts := durationUnit.FindStringSubmatch(match)
+		n, _ := strconv.ParseFloat(parts[1], 64)
+		if parts[2] == "w" {
+			n *= 7
+		}
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a duration", value)
+	}
+	return d, nil
+}
+
+// parseTimeValue parses the value of a date, datetime, time or duration variable.
+// Points in time are returned as time.Time, durations as time.Duration.
+func (v Variable) parseTimeValue(value any, now time.Time) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		switch value.(type) {
+		case time.Time, time.Duration:
+			return value, nil
+		}
+		return nil, fmt.Errorf("must be a string, got %T", value)
+	}
+
+	if v.Type == "duration" {
+		return ParseDuration(s)
+	}
+	return v.ParseTime(s, now)
+}
+
+// validateTimes validates the format, bounds, default and value of date, datetime, time and duration variables.
+func validateTimes(v Variable) []error {
+	var errors []error
+	now := time.Now()
+
+	parse := func(key string, value any) (any, bool) {
+		parsed, err := v.parseTimeValue(value, now)
+		if err != nil {
+			errors = append(errors, newValidationError(v, key, fmt.Sprintf("%s %s", key, err)))
+			return nil, false
+		}
+		return parsed, true
+	}
+
+	var min, max any
+	var hasMin, hasMax bool
+	if v.Min != nil {
+		min, hasMin = parse("min", v.Min)
+	}
+	if v.Max != nil {
+		max, hasMax = parse("max", v.Max)
+	}
+	if hasMin && hasMax && compareTimes(min, max) > 0 {
+		errors = append(errors, newValidationError(v, "min", "min must not be greater than max"))
+	}
+
+	if v.Default != nil {
+		parse("default", v.Default)
+	}
+
+	if v.Value != nil {
+		if value, ok := parse("value", v.Value); ok {
+			if hasMin && compareTimes(value, min) < 0 {
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be at least %v", v.Min)))
+			}
+			if hasMax && compareTimes(value, max) > 0 {
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be at most %v", v.Max)))
+			}
+		}
+	}
+
+	return errors
+}
+
+// compareTimes compares two values returned by parseTimeValue of the same variable.
+func compareTimes(a, b any) int {
+	switch a := a.(type) {
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case time.Duration:
+		switch d := b.(time.Duration); {
+		case a < d:
+			return -1
+		case a > d:
+			return 1
+		}
+	}
+	return 0
+}
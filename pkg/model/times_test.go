@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90m":   90 * time.Minute,
+		"7d":    7 * 24 * time.Hour,
+		"1w2d":  9 * 24 * time.Hour,
+		"-1d2h": -26 * time.Hour,
+		"1.5d":  36 * time.Hour,
+	}
+
+	for value, expected := range tests {
+		d, err := ParseDuration(value)
+		if err != nil {
+			t.Fatalf("%s: %v", value, err)
+		}
+		if d != expected {
+			t.Fatalf("%s: expected %s, got %s", value, expected, d)
+		}
+	}
+
+	if _, err := ParseDuration("soon"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2024, 3, 15, 13, 37, 0, 0, time.UTC)
+
+	tests := []struct {
+		variable Variable
+		value    string
+		expected string
+	}{
+		{Variable{Type: "date"}, "2024-01-02", "2024-01-02"},
+		{Variable{Type: "date"}, "now", "2024-03-15"},
+		{Variable{Type: "date"}, "+7d", "2024-03-22"},
+		{Variable{Type: "date", Format: "02.01.2006"}, "24.12.2024", "24.12.2024"},
+		{Variable{Type: "datetime"}, "-2h", "2024-03-15 11:37"},
+		{Variable{Type: "time"}, "+30m", "14:07"},
+	}
+
+	for _, test := range tests {
+		parsed, err := test.variable.ParseTime(test.value, now)
+		if err != nil {
+			t.Fatalf("%s: %v", test.value, err)
+		}
+		if formatted := parsed.Format(test.variable.Layout()); formatted != test.expected {
+			t.Fatalf("%s: expected %s, got %s", test.value, test.expected, formatted)
+		}
+	}
+}
+
+func TestValidateTimes(t *testing.T) {
+	v := Variable{Name: "Due", Type: "date", Min: "now", Max: "+30d", Value: "2000-01-01"}
+
+	errs := v.Validate()
+	if len(errs) != 1 || errs[0].(ValidationError).Message != "value must be at least now" {
+		t.Fatalf("expected value below min, got %v", errs)
+	}
+
+	v.Value = "+7d"
+	if errs := v.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+
+	v = Variable{Name: "Timeout", Type: "duration", Min: "1m", Max: "1h", Value: "2h"}
+	if errs := v.Validate(); len(errs) != 1 {
+		t.Fatalf("expected value above max, got %v", errs)
+	}
+}
@@ -2,42 +2,81 @@ package model
 
 import (
 	"fmt"
+	"github.com/goccy/go-yaml/token"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 )
 
 func (v Variable) Validate() []error {
 	var errors []error
 
+	// Items of arrays are validated like single values, see Template.ValidateValue
+	isArray := strings.HasSuffix(v.Type, "[]") || v.IsArray
+	if isArray {
+		v.Type = strings.TrimSuffix(v.Type, "[]")
+		v.Default = nil
+		v.Value = nil
+	}
+
 	// Check that type is set
 	if v.Type == "" {
-		errors = append(errors, newValidationError(v, "type is required"))
+		errors = append(errors, newValidationError(v, "type", "type is required"))
 	}
 
-	// Min and max are only applicable to number types
-	if v.Min != 0 || v.Max != 0 {
-		if v.Type != "number" {
-			errors = append(errors, newValidationError(v, "min and max are only applicable to number types"))
+	// Min and max are only applicable to number, integer, date, time and duration types
+	if v.Min != nil || v.Max != nil {
+		switch {
+		case v.Type == "number" || v.Type == "integer":
+			errors = append(errors, validateRangeBounds(v)...)
+		case isTimeType(v.Type) || v.Type == "duration":
+			// Validated together with the value, see validateTimes
+		default:
+			errors = append(errors, newValidationError(v, minMaxKey(v), "min and max are only applicable to number, integer, date, datetime, time and duration types"))
+		}
+	}
+
+	// Format is only applicable to date and time types
+	if v.Format != "" && !isTimeType(v.Type) {
+		errors = append(errors, newValidationError(v, "format", "format is only applicable to date, datetime and time types"))
+	}
+
+	// Min and max length are only applicable to text types
+	if v.MinLength != nil || v.MaxLength != nil {
+		if v.Type != "text" {
+			errors = append(errors, newValidationError(v, lengthKey(v), "minLength and maxLength are only applicable to text types"))
+		} else {
+			errors = append(errors, validateBounds(v, "minLength", v.MinLength, "maxLength", v.MaxLength)...)
+		}
+	}
+
+	// Min and max items are only applicable to arrays and multiselect types
+	if v.MinItems != nil || v.MaxItems != nil {
+		if !isArray && v.Type != "multiselect" {
+			errors = append(errors, newValidationError(v, itemsKey(v), "minItems and maxItems are only applicable to arrays and multiselect types"))
+		} else {
+			errors = append(errors, validateBounds(v, "minItems", v.MinItems, "maxItems", v.MaxItems)...)
 		}
 	}
 
 	// Regex is only applicable to text types
 	if v.Regex != "" {
 		if v.Type != "text" {
-			errors = append(errors, newValidationError(v, "regex is only applicable to text types"))
+			errors = append(errors, newValidationError(v, "regex", "regex is only applicable to text types"))
 		}
 	}
 
 	// Multiline is only applicable to text types
 	if v.Multiline {
 		if v.Type != "text" {
-			errors = append(errors, newValidationError(v, "multiline is only applicable to text types"))
+			errors = append(errors, newValidationError(v, "multiline", "multiline is only applicable to text types"))
 		}
 	}
 
 	// Options are only applicable to select and multiselect types
 	if len(v.Options) > 0 {
 		if v.Type != "select" && v.Type != "multiselect" {
-			errors = append(errors, newValidationError(v, "options are only applicable to select and multiselect types"))
+			errors = append(errors, newValidationError(v, "options", "options are only applicable to select and multiselect types"))
 		}
 	}
 
@@ -50,36 +89,46 @@ func (v Variable) Validate() []error {
 			case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 				// noop
 			default:
-				errors = append(errors, newValidationError(v, fmt.Sprintf("default must be a number or nil, got %T", v.Default)))
+				errors = append(errors, newValidationError(v, "default", fmt.Sprintf("default must be a number or nil, got %T", v.Default)))
 			}
 		}
 
 		var value float64
 		if v.Value != nil {
 			var ok bool
-			value, ok = v.Value.(float64)
+			value, ok = asFloat(v.Value)
 			if !ok {
-				errors = append(errors, newValidationError(v, fmt.Sprintf("value must be a number, got %T", v.Value)))
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be a number, got %T", v.Value)))
 			}
 		}
 
-		if v.Min != 0 || v.Max != 0 { // min or max is set
-			if v.Min > v.Max {
-				errors = append(errors, newValidationError(v, "min must be less than max"))
+		if v.Value != nil {
+			errors = append(errors, validateRange(v, value)...)
+		}
+	case "integer":
+		// Default value must be a whole number or nil
+		if v.Default != nil {
+			if _, ok := asInteger(v.Default); !ok {
+				errors = append(errors, newValidationError(v, "default", fmt.Sprintf("default must be an integer or nil, got %T", v.Default)))
 			}
+		}
 
-			if v.Value != nil {
-				if value < v.Min || value > v.Max {
-					errors = append(errors, newValidationError(v, "value must be between min and max"))
-				}
+		if v.Value != nil {
+			value, ok := asInteger(v.Value)
+			if !ok {
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be an integer, got %v", v.Value)))
+			} else {
+				errors = append(errors, validateRange(v, float64(value))...)
 			}
 		}
+	case "date", "datetime", "time", "duration":
+		errors = append(errors, validateTimes(v)...)
 	case "text":
 		// Default value must be a string or nil
 		if v.Default != nil {
 			_, ok := v.Default.(string)
 			if !ok {
-				errors = append(errors, newValidationError(v, fmt.Sprintf("default must be a string or nil, got %T", v.Default)))
+				errors = append(errors, newValidationError(v, "default", fmt.Sprintf("default must be a string or nil, got %T", v.Default)))
 			}
 		}
 
@@ -88,21 +137,28 @@ func (v Variable) Validate() []error {
 			var ok bool
 			value, ok = v.Value.(string)
 			if !ok {
-				errors = append(errors, newValidationError(v, fmt.Sprintf("value must be a string, got %T", v.Value)))
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be a string, got %T", v.Value)))
 			}
 		}
 
 		// Validate regex
 		if v.Regex != "" {
-			if v.Value != nil {
-				re, err := regexp.Compile(v.Regex)
-				if err != nil {
-					errors = append(errors, newValidationError(v, "invalid regex"))
-				}
+			re, err := regexp.Compile(v.Regex)
+			if err != nil {
+				errors = append(errors, newValidationError(v, "regex", "invalid regex"))
+			} else if v.Value != nil && !re.MatchString(value) {
+				errors = append(errors, newValidationError(v, "value", "value does not match regex"))
+			}
+		}
 
-				if !re.MatchString(value) {
-					errors = append(errors, newValidationError(v, "value does not match regex"))
-				}
+		// Validate length
+		if v.Value != nil {
+			length := utf8.RuneCountInString(value)
+			if v.MinLength != nil && length < *v.MinLength {
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be at least %d characters long", *v.MinLength)))
+			}
+			if v.MaxLength != nil && length > *v.MaxLength {
+				errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be at most %d characters long", *v.MaxLength)))
 			}
 		}
 
@@ -110,63 +166,58 @@ func (v Variable) Validate() []error {
 		if v.Value != nil {
 			_, ok := v.Value.(bool)
 			if !ok {
-				errors = append(errors, newValidationError(v, "value must be a boolean"))
+				errors = append(errors, newValidationError(v, "value", "value must be a boolean"))
 			}
 		}
 
 	case "select":
 		if len(v.Options) == 0 {
-			errors = append(errors, newValidationError(v, "options are required"))
+			errors = append(errors, newValidationError(v, "options", "options are required"))
 		}
 
-		if v.Value != nil {
-			var ok bool
-			_, ok = v.Value.(string)
-			if !ok {
-				errors = append(errors, newValidationError(v, "value must be a string"))
-			}
+		// Value must be the value of an option
+		if v.Value != nil && len(v.Options) > 0 && !isOptionValue(v.Options, v.Value) {
+			errors = append(errors, newValidationError(v, "value", "value is not in options"))
 		}
 
 	case "multiselect":
 		if len(v.Options) == 0 {
-			errors = append(errors, newValidationError(v, "options are required"))
+			errors = append(errors, newValidationError(v, "options", "options are required"))
 		}
 
 		if v.Value != nil {
-			// Value must be either string or string slice
-			switch v.Value.(type) {
-			case string:
-				// Check if value is in options
-				found := false
-				for _, o := range v.Options {
-					if v.Value == o.Value {
-						found = true
-						break
-					}
-				}
+			// Lists decoded from YAML or JSON contain any values
+			if values, ok := v.Value.([]any); ok {
+				v.Value = toStrings(values)
+			}
 
-				if !found {
-					errors = append(errors, newValidationError(v, "value is not in options"))
-				}
+			// A string contains the values separated by ";", the same as defaults of multiselect variables
+			if value, ok := v.Value.(string); ok {
+				v.Value = strings.Split(value, ";")
+			}
 
+			// Value must be either string or string slice
+			switch v.Value.(type) {
 			case []string:
+				errors = append(errors, validateItems(v, len(v.Value.([]string)))...)
+
 				// Check if all values are in options
 				for _, value := range v.Value.([]string) {
 					found := false
 					for _, o := range v.Options {
-						if value == o.Value {
+						if value == fmt.Sprint(o.ResolvedValue()) {
 							found = true
 							break
 						}
 					}
 
 					if !found {
-						errors = append(errors, newValidationError(v, "value is not in options"))
+						errors = append(errors, newValidationError(v, "value", "value is not in options"))
 					}
 				}
 
 			default:
-				errors = append(errors, newValidationError(v, "value must be a string or string slice"))
+				errors = append(errors, newValidationError(v, "value", "value must be a string or string slice"))
 			}
 		}
 
@@ -182,8 +233,17 @@ func (v Variable) Validate() []error {
 func (t Template) Validate() []error {
 	var errors []error
 
-	if t.Template == "" {
-		errors = append(errors, fmt.Errorf("template is required"))
+	if t.Template == "" && len(t.Files) == 0 {
+		errors = append(errors, ValidationError{Message: "template or files are required", location: []string{"template"}})
+	}
+
+	for i, f := range t.Files {
+		if f.Path == "" {
+			errors = append(errors, ValidationError{
+				Message:  fmt.Sprintf("file %d: path is required", i+1),
+				location: []string{"files", fmt.Sprintf("#%d", i)},
+			})
+		}
 	}
 
 	for _, v := range t.Variables {
@@ -191,8 +251,20 @@ func (t Template) Validate() []error {
 		if errs != nil {
 			errors = append(errors, errs...)
 		}
+
+		// Single values are already validated by Variable.Validate
+		if _, ok := t.Structures[strings.TrimSuffix(v.Type, "[]")]; ok || v.IsArray || strings.HasSuffix(v.Type, "[]") {
+			errors = append(errors, t.ValidateValue(v)...)
+		}
 	}
 
+	errors = append(errors, t.validateStructures()...)
+	errors = append(errors, t.validateTypes()...)
+	errors = append(errors, t.validateConditions()...)
+
+	referenceErrors, _ := t.checkReferences()
+	errors = append(errors, referenceErrors...)
+
 	if len(errors) > 0 {
 		return errors
 	}
@@ -200,6 +272,162 @@ func (t Template) Validate() []error {
 	return nil
 }
 
-func newValidationError(v Variable, message string) error {
-	return fmt.Errorf("variable %s: %s", v.Name, message)
+// isOptionValue reports whether the value is the resolved value of one of the options.
+func isOptionValue(options []Option, value any) bool {
+	for _, o := range options {
+		if fmt.Sprint(value) == fmt.Sprint(o.ResolvedValue()) {
+			return true
+		}
+	}
+	return false
+}
+
+func toStrings(values []any) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = fmt.Sprint(value)
+	}
+	return result
+}
+
+// ValidationError is an error in the definition or the value of a variable.
+type ValidationError struct {
+	// Variable is the name of the variable.
+	Variable string `json:"variable,omitempty"`
+	// Path is the path of the invalid value, e.g. "Users[1].Name".
+	Path string `json:"path,omitempty"`
+	// Message describes the error.
+	Message string `json:"message"`
+	// Rule is the lint rule that found the error, see Template.Lint.
+	Rule string `json:"rule,omitempty"`
+	// File is the name of the template file, if known.
+	File string `json:"file,omitempty"`
+	// Line is the line of the invalid definition in the template source, starting at 1.
+	Line int `json:"line,omitempty"`
+	// Column is the column of the invalid definition in the template source, starting at 1.
+	Column int `json:"column,omitempty"`
+	// Snippet is the source around the invalid definition.
+	Snippet string `json:"snippet,omitempty"`
+
+	// location is the path of the invalid definition in the template source,
+	// e.g. ["variables", "name=Age", "min"]. See Locate.
+	location []string
+	// token is the source token of the invalid definition.
+	token *token.Token
+}
+
+func (e ValidationError) Error() string {
+	message := e.Message
+	if e.Path != "" {
+		message = fmt.Sprintf("variable %s: %s", e.Path, e.Message)
+	}
+
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, message)
+	}
+	return message
+}
+
+func newValidationError(v Variable, key, message string) error {
+	return ValidationError{
+		Variable: v.Name,
+		Path:     v.Name,
+		Message:  message,
+		location: []string{"variables", "name=" + v.Name, key},
+	}
+}
+
+// minMaxKey returns the key of min or max, whichever is set.
+func minMaxKey(v Variable) string {
+	if v.Min != nil {
+		return "min"
+	}
+	return "max"
+}
+
+// lengthKey returns the key of minLength or maxLength, whichever is set.
+func lengthKey(v Variable) string {
+	if v.MinLength != nil {
+		return "minLength"
+	}
+	return "maxLength"
+}
+
+// itemsKey returns the key of minItems or maxItems, whichever is set.
+func itemsKey(v Variable) string {
+	if v.MinItems != nil {
+		return "minItems"
+	}
+	return "maxItems"
+}
+
+// validateRangeBounds checks that min and max of a number or integer variable are numbers and min is not greater than max.
+func validateRangeBounds(v Variable) []error {
+	var errors []error
+	min, minOK := asFloat(v.Min)
+	if v.Min != nil && !minOK {
+		errors = append(errors, newValidationError(v, "min", fmt.Sprintf("min must be a number, got %T", v.Min)))
+	}
+	max, maxOK := asFloat(v.Max)
+	if v.Max != nil && !maxOK {
+		errors = append(errors, newValidationError(v, "max", fmt.Sprintf("max must be a number, got %T", v.Max)))
+	}
+	if minOK && maxOK && min > max {
+		errors = append(errors, newValidationError(v, "min", "min must not be greater than max"))
+	}
+	return errors
+}
+
+// validateRange checks that the value of a number or integer variable is within min and max.
+func validateRange(v Variable, value float64) []error {
+	var errors []error
+	if min, ok := asFloat(v.Min); ok && value < min {
+		errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be at least %v", v.Min)))
+	}
+	if max, ok := asFloat(v.Max); ok && value > max {
+		errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must be at most %v", v.Max)))
+	}
+	return errors
+}
+
+// validateBounds checks that optional lower and upper bounds are not negative and the lower bound is not greater than the upper bound.
+func validateBounds(v Variable, minKey string, min *int, maxKey string, max *int) []error {
+	var errors []error
+	if min != nil && *min < 0 {
+		errors = append(errors, newValidationError(v, minKey, fmt.Sprintf("%s must not be negative", minKey)))
+	}
+	if max != nil && *max < 0 {
+		errors = append(errors, newValidationError(v, maxKey, fmt.Sprintf("%s must not be negative", maxKey)))
+	}
+	if min != nil && max != nil && *min > *max {
+		errors = append(errors, newValidationError(v, minKey, fmt.Sprintf("%s must not be greater than %s", minKey, maxKey)))
+	}
+	return errors
+}
+
+// validateItems checks the number of items of a value against minItems and maxItems.
+func validateItems(v Variable, count int) []error {
+	var errors []error
+	if v.MinItems != nil && count < *v.MinItems {
+		errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must have at least %d items", *v.MinItems)))
+	}
+	if v.MaxItems != nil && count > *v.MaxItems {
+		errors = append(errors, newValidationError(v, "value", fmt.Sprintf("value must have at most %d items", *v.MaxItems)))
+	}
+	return errors
+}
+
+// ValidateValue validates a single value of the variable, e.g. an entered value or an item of an array.
+// Only errors of the value are returned, not errors of the definition of the variable.
+func (v Variable) ValidateValue() []error {
+	v.IsArray = false
+	v.Type = strings.TrimSuffix(v.Type, "[]")
+
+	var errors []error
+	for _, err := range v.Validate() {
+		if validationError, ok := err.(ValidationError); ok && validationError.location[len(validationError.location)-1] == "value" {
+			errors = append(errors, validationError)
+		}
+	}
+	return errors
 }
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+)
+
+func TestValidateIntegerDefault(t *testing.T) {
+	v := Variable{Name: "Count", Type: "integer", Default: "five"}
+
+	errs := v.Validate()
+	if len(errs) != 1 || errs[0].(ValidationError).Message != "default must be an integer or nil, got string" {
+		t.Fatalf("expected invalid default, got %v", errs)
+	}
+
+	v.Default = 5
+	if errs := v.Validate(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateMultiselectItems(t *testing.T) {
+	minItems, maxItems := 2, 2
+	v := Variable{
+		Name:     "Features",
+		Type:     "multiselect",
+		Options:  []Option{{Value: "a"}, {Value: "b"}, {Value: "c"}},
+		MinItems: &minItems,
+		MaxItems: &maxItems,
+	}
+
+	tests := map[string]string{
+		"a":     "value must have at least 2 items",
+		"a;b;c": "value must have at most 2 items",
+		"a;d":   "value is not in options",
+		"a;b":   "",
+		"b;c":   "",
+	}
+
+	for value, expected := range tests {
+		v.Value = value
+		errs := v.Validate()
+		if expected == "" {
+			if len(errs) != 0 {
+				t.Fatalf("%s: expected no errors, got %v", value, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].(ValidationError).Message != expected {
+			t.Fatalf("%s: expected %q, got %v", value, expected, errs)
+		}
+	}
+}
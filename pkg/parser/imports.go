@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/Masterminds/sprig/v3"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"github.com/gttp-cli/gttp/pkg/utils"
+	neturl "net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ResolveImports adds the structures, variables and named templates of all imports to the template.
+// Imports are resolved recursively, relative to the source (file path or URL) of the importing template.
+// Imported variables are added before the variables of the template.
+// It fails on import cycles and if a name is defined by more than one template.
+func ResolveImports(template model.Template, source string) (model.Template, error) {
+	template, _, err := ResolveImportSources(template, source)
+	return template, err
+}
+
+// ResolveImportSources resolves the imports like ResolveImports and also returns the sources of all imports that were read,
+// including the ones that failed to be read, e.g. to resolve the imports again when one of them changes.
+func ResolveImportSources(template model.Template, source string) (model.Template, []string, error) {
+	r := importResolver{
+		structures:  make(map[string]string),
+		variables:   make(map[string]string),
+		definitions: make(map[string]string),
+		imported:    make(map[string]bool),
+		read:        make(map[string]bool),
+	}
+
+	if !isURL(source) {
+		source = filepath.Clean(source)
+	}
+
+	template, err := r.resolve(template, source, nil)
+
+	sources := make([]string, 0, len(r.read))
+	for s := range r.read {
+		sources = append(sources, s)
+	}
+	sort.Strings(sources)
+
+	return template, sources, err
+}
+
+type importResolver struct {
+	// structures, variables and definitions map names to the source that defines them.
+	structures  map[string]string
+	variables   map[string]string
+	definitions map[string]string
+	// imported contains the sources whose definitions were already added.
+	imported map[string]bool
+	// read contains the sources that were read.
+	read map[string]bool
+}
+
+func (r importResolver) resolve(tmpl model.Template, source string, stack []string) (model.Template, error) {
+	if err := r.register(tmpl, source); err != nil {
+		return tmpl, err
+	}
+
+	stack = append(stack, source)
+	for _, imp := range tmpl.Imports {
+		location := resolveImportLocation(source, imp)
+
+		for _, s := range stack {
+			if s == location {
+				return tmpl, fmt.Errorf("import cycle: %s -> %s", strings.Join(stack, " -> "), location)
+			}
+		}
+
+		r.read[location] = true
+
+		var content string
+		var err error
+		if isURL(location) {
+			content, err = utils.ReadURL(location)
+		} else {
+			content, err = utils.ReadFile(location)
+		}
+		if err != nil {
+			return tmpl, fmt.Errorf("failed to import %s: %w", imp, err)
+		}
+
+		imported, err := model.FromYAML(content)
+		if err != nil {
+			return tmpl, fmt.Errorf("failed to import %s: %w", imp, err)
+		}
+
+		imported, err = r.resolve(imported, location, stack)
+		if err != nil {
+			return tmpl, err
+		}
+
+		tmpl = merge(tmpl, imported)
+
+		if imported.Template != "" && !r.imported[location] {
+			tmpl.Definitions = append(tmpl.Definitions, imported.Template)
+		}
+		r.imported[location] = true
+	}
+
+	return tmpl, nil
+}
+
+// register records the names that are defined by the template and fails if they are already defined by another source.
+func (r importResolver) register(tmpl model.Template, source string) error {
+	for name := range tmpl.Structures {
+		if err := registerName(r.structures, "structure", name, source); err != nil {
+			return err
+		}
+	}
+
+	for _, variable := range tmpl.Variables {
+		if err := registerName(r.variables, "variable", variable.Name, source); err != nil {
+			return err
+		}
+	}
+
+	t, err := template.New("").Funcs(sprig.FuncMap()).Parse(tmpl.Template)
+	if err != nil {
+		return nil // Syntax errors are reported when rendering.
+	}
+
+	for _, defined := range t.Templates() {
+		if defined.Name() == "" {
+			continue
+		}
+
+		if err := registerName(r.definitions, "template", defined.Name(), source); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func registerName(names map[string]string, kind, name, source string) error {
+	if existing, ok := names[name]; ok && existing != source {
+		return fmt.Errorf("%s %s is defined in both %s and %s", kind, name, existing, source)
+	}
+
+	names[name] = source
+	return nil
+}
+
+// merge adds the structures, variables and definitions of the imported template that are not yet defined in the template.
+func merge(tmpl, imported model.Template) model.Template {
+	structures := make(map[string][]model.Variable, len(tmpl.Structures)+len(imported.Structures))
+	for name, fields := range imported.Structures {
+		structures[name] = fields
+	}
+	for name, fields := range tmpl.Structures {
+		structures[name] = fields
+	}
+	tmpl.Structures = structures
+
+	var variables []model.Variable
+	for _, variable := range imported.Variables {
+		if !hasVariable(tmpl.Variables, variable.Name) {
+			variables = append(variables, variable)
+		}
+	}
+	tmpl.Variables = append(variables, tmpl.Variables...)
+
+	tmpl.Definitions = append(tmpl.Definitions, imported.Definitions...)
+
+	return tmpl
+}
+
+func hasVariable(variables []model.Variable, name string) bool {
+	for _, variable := range variables {
+		if variable.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveImportLocation resolves the location of an import relative to the source of the importing template.
+func resolveImportLocation(source, imp string) string {
+	if isURL(imp) {
+		return imp
+	}
+
+	if isURL(source) {
+		base, err := neturl.Parse(source)
+		if err == nil {
+			if ref, err := neturl.Parse(imp); err == nil {
+				return base.ResolveReference(ref).String()
+			}
+		}
+	}
+
+	if filepath.IsAbs(imp) || source == "" {
+		return filepath.Clean(imp)
+	}
+
+	return filepath.Join(filepath.Dir(source), imp)
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
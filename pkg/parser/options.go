@@ -0,0 +1,63 @@
+package parser
+
+// Option configures how a template is parsed.
+type Option func(*options)
+
+type options struct {
+	envPrefix string
+	noEnv     bool
+	prompter  Prompter
+	defaults  map[string]any
+	entered   map[string]any
+}
+
+// WithDefaults overrides the default values that are offered when asking for input, e.g. with previously entered values.
+// The keys are the names of the variables.
+func WithDefaults(defaults map[string]any) Option {
+	return func(o *options) {
+		o.defaults = defaults
+	}
+}
+
+// WithEntered collects the values that were entered interactively in the given map, keyed by the name of the variable.
+// Values that were predefined or read from the environment are not added.
+func WithEntered(entered map[string]any) Option {
+	return func(o *options) {
+		o.entered = entered
+	}
+}
+
+// WithPrompter sets the prompter that is used to ask for input.
+// By default, the user is asked in the terminal using PtermPrompter.
+func WithPrompter(prompter Prompter) Option {
+	return func(o *options) {
+		o.prompter = prompter
+	}
+}
+
+// WithEnvPrefix makes variables read their value from environment variables named by the prefix and the variable name,
+// e.g. "GTTP_Name" for the prefix "GTTP_" and the variable "Name".
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithoutEnv disables reading values from environment variables, including those named by the env key of variables.
+// Use it for templates that are not trusted, e.g. templates that are sent to the server.
+func WithoutEnv() Option {
+	return func(o *options) {
+		o.noEnv = true
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		prompter: PtermPrompter{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -1,26 +1,27 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"github.com/Masterminds/sprig/v3"
 	"github.com/expr-lang/expr"
 	"github.com/gttp-cli/gttp/pkg/model"
-	"github.com/pterm/pterm"
+	"github.com/gttp-cli/gttp/pkg/values"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 )
 
 // ParseTemplate parses the template and updates its variables with filled values.
-func ParseTemplate(template model.Template) (model.Template, error) {
+func ParseTemplate(template model.Template, opts ...Option) (model.Template, error) {
+	o := newOptions(opts)
+
 	// Validate the template
-	validationErrors := template.Validate()
-	if validationErrors != nil {
-		var errors []string
-		for _, err := range validationErrors {
-			errors = append(errors, fmt.Sprintf("- %s", err))
-		}
-		return template, fmt.Errorf("template validation failed:\n\n%s", strings.Join(errors, "\n"))
+	if err := validateTemplate(template); err != nil {
+		return template, err
 	}
 
 	for i, variable := range template.Variables {
@@ -29,7 +30,7 @@ func ParseTemplate(template model.Template) (model.Template, error) {
 		}
 
 		var err error
-		template.Variables[i].Value, err = processVariable(variable, template)
+		template.Variables[i].Value, err = processVariable(variable, template, o)
 		if err != nil {
 			return template, err
 		}
@@ -38,31 +39,184 @@ func ParseTemplate(template model.Template) (model.Template, error) {
 	return template, nil
 }
 
-func processVariable(variable model.Variable, template model.Template) (any, error) {
-	if variable.Condition != "" && !evaluateCondition(variable.Condition, template) {
+// FillTemplate fills all variables without a value with their default value, without asking for input.
+// It fails with a list of all variables that have neither a value nor a default value.
+func FillTemplate(template model.Template, opts ...Option) (model.Template, error) {
+	o := newOptions(opts)
+
+	// Validate the template
+	if err := validateTemplate(template); err != nil {
+		return template, err
+	}
+
+	var missing MissingValuesError
+	for i, variable := range template.Variables {
+		if variable.Value != nil || variable.Type == "section" {
+			continue
+		}
+
+		if variable.Condition != "" && !evaluateCondition(variable.Condition, conditionEnv(nil, template)) {
+			continue // Condition not met, skip variable.
+		}
+
+		value, ok, err := lookupEnv(variable, template, o)
+		if err != nil {
+			return template, err
+		}
+
+		if ok {
+			template.Variables[i].Value = value
+			continue
+		}
+
+		if variable.Default == nil {
+			missing.Variables = append(missing.Variables, variable.Name)
+			continue
+		}
+
+		template.Variables[i].Value = resolveDefault(variable, template)
+	}
+
+	if len(missing.Variables) > 0 {
+		return template, missing
+	}
+
+	return template, nil
+}
+
+// resolveDefault converts the default value of the variable to the value that is stored when the default is chosen interactively,
+// e.g. option names of select and multiselect variables to their option values.
+func resolveDefault(variable model.Variable, template model.Template) any {
+	def := variable.Default
+	if s, ok := def.(string); ok && variable.Type == "multiselect" {
+		def = strings.Split(s, ";") // Same as the default options offered by askForInput
+	}
+	return values.Coerce(variable, def, template.Structures)
+}
+
+// MissingValuesError is returned when variables have neither a value nor a default value.
+type MissingValuesError struct {
+	// Variables are the names of the variables without a value.
+	Variables []string
+}
+
+func (e MissingValuesError) Error() string {
+	var missing []string
+	for _, name := range e.Variables {
+		missing = append(missing, fmt.Sprintf("- %s", name))
+	}
+
+	return fmt.Sprintf("missing values for variables:\n\n%s", strings.Join(missing, "\n"))
+}
+
+func validateTemplate(template model.Template) error {
+	if validationErrors := template.Validate(); validationErrors != nil {
+		return ValidationFailed(validationErrors, false)
+	}
+
+	return nil
+}
+
+// ValidationFailed returns an error that lists the validation errors.
+// Validation errors with a known position are followed by the highlighted source, see model.Locate.
+func ValidationFailed(validationErrors []error, colored bool) error {
+	var lines []string
+	for _, err := range validationErrors {
+		var validationError model.ValidationError
+		if errors.As(err, &validationError) {
+			lines = append(lines, fmt.Sprintf("- %s", indent(validationError.Format(colored))))
+		} else {
+			lines = append(lines, fmt.Sprintf("- %s", err))
+		}
+	}
+	return fmt.Errorf("template validation failed:\n\n%s", strings.Join(lines, "\n"))
+}
+
+// indent indents all non-empty lines but the first, so multi-line errors line up in a list.
+func indent(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = "  " + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func processVariable(variable model.Variable, template model.Template, o options) (any, error) {
+	if variable.Condition != "" && !evaluateCondition(variable.Condition, conditionEnv(nil, template)) {
 		return nil, nil // Condition not met, skip variable.
 	}
 
+	if value, ok, err := lookupEnv(variable, template, o); err != nil || ok {
+		return value, err
+	}
+
 	if strings.HasSuffix(variable.Type, "[]") {
 		variable.IsArray = true
 		variable.Type = strings.TrimSuffix(variable.Type, "[]")
 	}
 
+	if value, ok := o.defaults[variable.Name]; ok && !variable.IsArray {
+		variable.Default = promptDefault(variable, value)
+	}
+
+	var value any
+	var err error
 	if variable.IsArray {
-		return processArrayVariable(variable, template)
+		value, err = processArrayVariable(variable, template, o.prompter, "")
+	} else {
+		value, err = processSingleVariable(variable, template, o.prompter, "")
+	}
+
+	if err == nil && value != nil && o.entered != nil {
+		o.entered[variable.Name] = value
+	}
+
+	return value, err
+}
+
+// lookupEnv returns the value of the variable from the environment, coerced to the type of the variable.
+func lookupEnv(variable model.Variable, template model.Template, o options) (any, bool, error) {
+	if o.noEnv {
+		return nil, false, nil
+	}
+
+	name := variable.Env
+	if name == "" && o.envPrefix != "" {
+		name = o.envPrefix + variable.Name
+	}
+
+	if name == "" {
+		return nil, false, nil
+	}
+
+	env, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, false, nil
+	}
+
+	variable.Value = values.Coerce(variable, env, template.Structures)
+	if validationErrors := template.ValidateValue(variable); validationErrors != nil {
+		var errors []string
+		for _, err := range validationErrors {
+			errors = append(errors, fmt.Sprintf("- %s", err))
+		}
+		return nil, false, fmt.Errorf("invalid value in environment variable %s:\n\n%s", name, strings.Join(errors, "\n"))
 	}
 
-	return processSingleVariable(variable, template)
+	return variable.Value, true, nil
 }
 
-func evaluateCondition(condition string, template model.Template) bool {
+// evaluateCondition evaluates the condition against the given environment.
+// Conditions that fail to compile or run are not met.
+func evaluateCondition(condition string, env map[string]any) bool {
 	exp, err := expr.Compile(condition)
 	if err != nil {
 		return false
 	}
 
-	variableValues := extractVariableValues(template)
-	result, err := expr.Run(exp, variableValues)
+	result, err := expr.Run(exp, env)
 	if err != nil {
 		return false
 	}
@@ -70,51 +224,138 @@ func evaluateCondition(condition string, template model.Template) bool {
 	return result == true
 }
 
-func processArrayVariable(variable model.Variable, template model.Template) ([]interface{}, error) {
+// conditionEnv returns the environment of a condition.
+// It contains the given sibling values, the values of the top-level variables if there are no siblings,
+// and the values of the top-level variables as $root.
+func conditionEnv(siblings map[string]any, template model.Template) map[string]any {
+	root := extractVariableValues(template)
+	if siblings == nil {
+		siblings = root
+	}
+
+	env := make(map[string]any, len(siblings)+1)
+	for name, value := range siblings {
+		env[name] = value
+	}
+	env[model.RootVariable] = root
+
+	return env
+}
+
+func processArrayVariable(variable model.Variable, template model.Template, prompter Prompter, parentPath string) ([]interface{}, error) {
+	path := joinPath(parentPath, variable.Name)
+
 	var values []interface{}
-	for {
-		val, err := askForVariableValue(variable, template)
+	if variable.MaxItems != nil && *variable.MaxItems == 0 {
+		return values, nil
+	}
+
+	for i := 0; ; i++ {
+		val, err := askForVariableValue(variable, template, prompter, parentPath, fmt.Sprintf("%s[%d]", path, i))
 		if err != nil {
 			return nil, err
 		}
 
 		values = append(values, val)
-		if !AskToContinue() {
+
+		// Only ask for more items between minItems and maxItems
+		if variable.MaxItems != nil && len(values) >= *variable.MaxItems {
+			break
+		}
+		if variable.MinItems != nil && len(values) < *variable.MinItems {
+			continue
+		}
+
+		more, err := AskToContinue(prompter)
+		if err != nil {
+			return nil, err
+		}
+
+		if !more {
 			break
 		}
 	}
 	return values, nil
 }
 
-func processSingleVariable(variable model.Variable, template model.Template) (interface{}, error) {
-	return askForVariableValue(variable, template)
+func processSingleVariable(variable model.Variable, template model.Template, prompter Prompter, parentPath string) (interface{}, error) {
+	return askForVariableValue(variable, template, prompter, parentPath, joinPath(parentPath, variable.Name))
 }
 
-func askForVariableValue(variable model.Variable, template model.Template) (any, error) {
+// askForVariableValue asks for a single value of the variable.
+// The parent path is shown as prompt prefix, the path is the path of the value, e.g. "Services[2].Owner".
+func askForVariableValue(variable model.Variable, template model.Template, prompter Prompter, parentPath, path string) (any, error) {
 	if structVars, ok := template.Structures[variable.Type]; ok {
-		return ParseCustomType(variable, structVars)
+		// Offer the fields of a default structure value as field defaults
+		if defaults, ok := variable.Default.(map[string]any); ok {
+			structVars = append([]model.Variable(nil), structVars...)
+			for i, field := range structVars {
+				if value, ok := defaults[field.Name]; ok && !field.IsArray && !strings.HasSuffix(field.Type, "[]") {
+					structVars[i].Default = promptDefault(field, value)
+				}
+			}
+		}
+
+		return ParseCustomType(prompter, path, structVars, template)
 	}
-	return AskForInput(variable, "")
+	return AskForInput(prompter, variable, parentPath)
+}
+
+func joinPath(parentPath, name string) string {
+	if parentPath == "" {
+		return name
+	}
+
+	return parentPath + "." + name
+}
+
+// promptDefault converts a value to a default value that can be offered when asking for input.
+// Values of select and multiselect variables are converted back to the names of their options.
+func promptDefault(variable model.Variable, value any) any {
+	optionName := func(value any) any {
+		for _, option := range variable.Options {
+			if fmt.Sprint(option.ResolvedValue()) == fmt.Sprint(value) {
+				return option.Name
+			}
+		}
+		return value
+	}
+
+	switch variable.Type {
+	case "select":
+		return optionName(value)
+	case "multiselect":
+		items, ok := value.([]any)
+		if !ok {
+			return value
+		}
+
+		names := make([]any, len(items))
+		for i, item := range items {
+			names[i] = optionName(item)
+		}
+		return names
+	}
+
+	return value
 }
 
 func extractVariableValues(template model.Template) map[string]interface{} {
 	values := make(map[string]interface{})
 	for _, variable := range template.Variables {
-		values[variable.Name] = variable.Value
+		values[variable.Name] = template.TypedValue(variable)
 	}
 	return values
 }
 
-func AskToContinue() bool {
-	res, _ := pterm.DefaultInteractiveConfirm.Show("Add more?")
-	return res
+// AskToContinue asks the user whether more items should be added to an array.
+func AskToContinue(prompter Prompter) (bool, error) {
+	return prompter.Confirm("Add more?", false)
 }
 
-// AskForInput simulates asking the user for input based on the variable type and description.
-func AskForInput(variable model.Variable, prefix string) (any, error) {
-	var input any
-	var err error
-
+// Prompt returns the prompt that is shown when asking for the value of a variable.
+// The prefix is shown in brackets, e.g. the name of the structure variable a field belongs to.
+func Prompt(variable model.Variable, prefix string) string {
 	var prompt string
 
 	if prefix != "" {
@@ -127,28 +368,140 @@ func AskForInput(variable model.Variable, prefix string) (any, error) {
 		prompt += variable.Name
 	}
 
+	return prompt
+}
+
+// AskForInput asks the user for input based on the variable type and description.
+// It asks until a valid value is entered. Invalid values are shown with the reason and asked for again.
+func AskForInput(prompter Prompter, variable model.Variable, prefix string) (any, error) {
+	for {
+		input, err := askForInput(prompter, variable, prefix)
+
+		var messages []string
+		var invalid invalidInputError
+		if errors.As(err, &invalid) {
+			messages = append(messages, invalid.message)
+		} else if err != nil || input == nil {
+			return input, err
+		} else {
+			checked := variable
+			checked.Value = input
+			for _, err := range checked.ValidateValue() {
+				var validationError model.ValidationError
+				if errors.As(err, &validationError) {
+					messages = append(messages, validationError.Message)
+				} else {
+					messages = append(messages, err.Error())
+				}
+			}
+		}
+
+		if len(messages) == 0 {
+			return input, nil
+		}
+
+		if variable.ErrorMessage != "" {
+			messages = []string{variable.ErrorMessage}
+		}
+		if err := prompter.Error(strings.Join(messages, ", ")); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// invalidInputError is returned by askForInput if the entered value cannot be parsed, e.g. a number.
+type invalidInputError struct {
+	message string
+}
+
+func (e invalidInputError) Error() string {
+	return e.message
+}
+
+// askForInput asks the user for input once.
+func askForInput(prompter Prompter, variable model.Variable, prefix string) (any, error) {
+	var input any
+	var err error
+
+	prompt := Prompt(variable, prefix)
+
 	switch variable.Type {
 	case "text":
 		var def string
 		if variable.Default != nil {
 			def = fmt.Sprint(variable.Default)
 		}
-		input, err = pterm.DefaultInteractiveTextInput.WithMultiLine(variable.Multiline).WithDefaultValue(def).Show(prompt)
+		input, err = prompter.Text(prompt, def, variable.Multiline)
 		if input == "" {
 			input = nil
 		}
 	case "number":
 		var number float64
 		var answer string
-		answer, err = pterm.DefaultInteractiveTextInput.Show(prompt)
-		if answer != "" {
-			number, err = strconv.ParseFloat(answer, 64)
+		var def string
+		if variable.Default != nil {
+			def = fmt.Sprint(variable.Default)
+		}
+		answer, err = prompter.Number(prompt, def)
+		if err == nil && answer != "" {
+			number, err = strconv.ParseFloat(strings.TrimSpace(answer), 64)
+			if err != nil {
+				return nil, invalidInputError{message: fmt.Sprintf("%s is not a number", answer)}
+			}
 			input = number
 		}
+	case "integer":
+		var answer string
+		var def string
+		if variable.Default != nil {
+			def = fmt.Sprint(variable.Default)
+		}
+		answer, err = prompter.Number(prompt, def)
+		if err == nil && answer != "" {
+			integer, parseErr := strconv.ParseInt(strings.TrimSpace(answer), 10, 64)
+			if parseErr != nil {
+				return nil, invalidInputError{message: fmt.Sprintf("%s is not an integer", answer)}
+			}
+			input = integer
+		}
+	case "date", "datetime", "time":
+		// Relative defaults like "+7d" are offered as the resulting date
+		var def string
+		if variable.Default != nil {
+			def = fmt.Sprint(variable.Default)
+			if t, parseErr := variable.ParseTime(def, time.Now()); parseErr == nil {
+				def = t.Format(variable.Layout())
+			}
+		}
+
+		var answer string
+		answer, err = prompter.Text(prompt, def, false)
+		if err == nil && strings.TrimSpace(answer) != "" {
+			t, parseErr := variable.ParseTime(answer, time.Now())
+			if parseErr != nil {
+				return nil, invalidInputError{message: parseErr.Error()}
+			}
+			input = t.Format(variable.Layout())
+		}
+	case "duration":
+		var def string
+		if variable.Default != nil {
+			def = fmt.Sprint(variable.Default)
+		}
+
+		var answer string
+		answer, err = prompter.Text(prompt, def, false)
+		if err == nil && strings.TrimSpace(answer) != "" {
+			if _, parseErr := model.ParseDuration(answer); parseErr != nil {
+				return nil, invalidInputError{message: parseErr.Error()}
+			}
+			input = strings.TrimSpace(answer)
+		}
 	case "section":
-		pterm.DefaultSection.Println(variable.Name)
+		err = prompter.Section(variable.Name)
 	case "boolean":
-		input, err = pterm.DefaultInteractiveConfirm.Show(prompt)
+		def, _ := variable.Default.(bool)
+		input, err = prompter.Confirm(prompt, def)
 	case "select":
 		var options []string
 		for _, option := range variable.Options {
@@ -156,16 +509,12 @@ func AskForInput(variable model.Variable, prefix string) (any, error) {
 		}
 
 		defaultOption := fmt.Sprint(variable.Default)
-		input, err = pterm.DefaultInteractiveSelect.WithOptions(options).WithDefaultOption(defaultOption).Show(prompt)
+		input, err = prompter.Select(prompt, options, defaultOption)
 
 		// look if option has a value
 		for _, option := range variable.Options {
 			if option.Name == input {
-				if option.Value != nil {
-					input = option.Value
-				} else {
-					input = option.Name
-				}
+				input = option.ResolvedValue()
 			}
 		}
 
@@ -193,7 +542,19 @@ func AskForInput(variable model.Variable, prefix string) (any, error) {
 		if len(defaultOptions) == 0 {
 			defaultOptions = nil
 		}
-		input, err = pterm.DefaultInteractiveMultiselect.WithOptions(options).WithDefaultOptions(defaultOptions).Show(prompt)
+		var selected []string
+		selected, err = prompter.MultiSelect(prompt, options, defaultOptions)
+
+		// use the values of the selected options
+		selectedValues := make([]string, 0, len(selected))
+		for _, name := range selected {
+			for _, option := range variable.Options {
+				if option.Name == name {
+					selectedValues = append(selectedValues, fmt.Sprint(option.ResolvedValue()))
+				}
+			}
+		}
+		input = selectedValues
 	default:
 		return nil, fmt.Errorf("invalid variable type: %s", variable.Type)
 	}
@@ -206,24 +567,60 @@ func AskForInput(variable model.Variable, prefix string) (any, error) {
 }
 
 // ParseCustomType handles parsing of custom types by asking for input for each field of the custom type.
-func ParseCustomType(variable model.Variable, customType []model.Variable) (interface{}, error) {
+// Fields can be structures and arrays themselves. The path of the structure value is shown as prompt prefix of its fields.
+// Fields whose condition is not met are skipped. Conditions see the fields entered so far and the top-level variables as $root.
+func ParseCustomType(prompter Prompter, path string, customType []model.Variable, template model.Template) (interface{}, error) {
 	customValue := make(map[string]interface{})
-	var err error
+	siblings := make(map[string]any) // Typed values of the fields, as seen by conditions
 	for _, field := range customType {
-		customValue[field.Name], err = AskForInput(field, variable.Name)
+		if field.Condition != "" && !evaluateCondition(field.Condition, conditionEnv(siblings, template)) {
+			customValue[field.Name] = nil // Condition not met, skip field.
+			siblings[field.Name] = nil
+			continue
+		}
+
+		if strings.HasSuffix(field.Type, "[]") {
+			field.IsArray = true
+			field.Type = strings.TrimSuffix(field.Type, "[]")
+		}
+
+		var value any
+		var err error
+		if field.IsArray {
+			value, err = processArrayVariable(field, template, prompter, path)
+		} else {
+			value, err = processSingleVariable(field, template, prompter, path)
+		}
 		if err != nil {
 			return nil, err
 		}
+
+		customValue[field.Name] = value
+		field.Value = value
+		siblings[field.Name] = template.TypedValue(field)
 	}
 	return customValue, nil
 }
 
 func ParseGoTextTemplate(templateContent string, variables map[string]any) (string, error) {
+	return parseGoTextTemplate(templateContent, nil, variables)
+}
+
+// parseGoTextTemplate parses and executes the template content.
+// The named templates of the definitions can be used in the template content.
+func parseGoTextTemplate(templateContent string, definitions []string, variables map[string]any) (string, error) {
 	tmpl, err := template.New("template").Funcs(sprig.FuncMap()).Parse(templateContent)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse go template: %w", err)
 	}
 
+	for i, definition := range definitions {
+		_, err = tmpl.New(fmt.Sprintf("definition-%d", i)).Parse(definition)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse imported go template: %w", err)
+		}
+	}
+
 	var parsed strings.Builder
 	err = tmpl.Execute(&parsed, variables)
 	if err != nil {
@@ -235,5 +632,72 @@ func ParseGoTextTemplate(templateContent string, variables map[string]any) (stri
 
 func RenderTemplate(template model.Template) (string, error) {
 	variableValues := extractVariableValues(template)
-	return ParseGoTextTemplate(template.Template, variableValues)
+	return parseGoTextTemplate(template.Template, template.Definitions, variableValues)
+}
+
+// RenderedFile is a file that was rendered from the files of a template.
+type RenderedFile struct {
+	// Path is the rendered path of the file, relative to the output directory.
+	Path string
+	// Content is the rendered content of the file.
+	Content string
+	// Raw indicates that the content was copied verbatim, e.g. because it is binary.
+	Raw bool
+	// Mode is the permission of the file, or 0 for the default permission.
+	Mode os.FileMode
+}
+
+// RenderFiles renders the paths and contents of all files of the template whose condition is met.
+func RenderFiles(template model.Template) ([]RenderedFile, error) {
+	variableValues := extractVariableValues(template)
+
+	var files []RenderedFile
+	for _, file := range template.Files {
+		if file.Condition != "" && !evaluateCondition(file.Condition, conditionEnv(nil, template)) {
+			continue // Condition not met, skip file.
+		}
+
+		path, err := parseGoTextTemplate(file.Path, template.Definitions, variableValues)
+		if err != nil {
+			return nil, fmt.Errorf("file %s: %w", file.Path, err)
+		}
+
+		// Skip files with an empty file name, e.g. "{{ if .Docker }}Dockerfile{{ end }}"
+		segments := strings.Split(filepath.ToSlash(path), "/")
+		if strings.TrimSpace(segments[len(segments)-1]) == "" {
+			continue
+		}
+
+		// Empty directory names would silently move the file to another directory
+		if hasEmptySegment(segments[:len(segments)-1]) {
+			return nil, fmt.Errorf("file %s: path %q has an empty directory name", file.Path, path)
+		}
+
+		path = filepath.Clean(filepath.FromSlash(strings.TrimSpace(path)))
+		if path == "." || filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("file %s: path %q must be relative to the output directory", file.Path, path)
+		}
+
+		content := file.Content
+		if !file.Raw {
+			content, err = parseGoTextTemplate(file.Content, template.Definitions, variableValues)
+			if err != nil {
+				return nil, fmt.Errorf("file %s: %w", file.Path, err)
+			}
+		}
+
+		files = append(files, RenderedFile{Path: path, Content: content, Raw: file.Raw, Mode: file.Mode})
+	}
+
+	return files, nil
+}
+
+func hasEmptySegment(segments []string) bool {
+	for _, segment := range segments {
+		if strings.TrimSpace(segment) == "" {
+			return true
+		}
+	}
+
+	return false
 }
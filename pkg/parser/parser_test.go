@@ -0,0 +1,571 @@
+package parser
+
+import (
+	"errors"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTemplateCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		answers  []any
+		expected string
+	}{
+		{name: "condition met", answers: []any{true, "john"}, expected: "The username is john"},
+		{name: "condition not met", answers: []any{false}, expected: "No user was added"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl := model.Template{
+				Variables: []model.Variable{
+					{Name: "AddUser", Type: "boolean"},
+					{Name: "Username", Type: "text", Condition: "AddUser"},
+				},
+				Template: `{{ if .AddUser }}The username is {{ .Username }}{{ else }}No user was added{{ end }}`,
+			}
+
+			prompter := NewScriptedPrompter(test.answers...)
+			tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(prompter.Answers) != 0 {
+				t.Fatalf("expected all answers to be used, %d left", len(prompter.Answers))
+			}
+
+			result, err := RenderTemplate(tmpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseTemplateArray(t *testing.T) {
+	tmpl := model.Template{
+		Structures: map[string][]model.Variable{
+			"person": {
+				{Name: "Name", Type: "text"},
+				{Name: "Age", Type: "number", Default: 18.0},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Tags", Type: "text[]"},
+			{Name: "Users", Type: "person[]"},
+		},
+		Template: `{{ range .Tags }}{{ . }};{{ end }}{{ range .Users }}{{ .Name }}={{ .Age }};{{ end }}`,
+	}
+
+	prompter := NewScriptedPrompter(
+		"a", true, "b", false, // Tags
+		"John", "42", true, "Jane", "", false, // Users
+	)
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "a;b;John=42;Jane=18;"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+
+	expectedPrompts := []string{
+		"Tags", "Add more?", "Tags", "Add more?",
+		"[Users[0]] Name", "[Users[0]] Age", "Add more?", "[Users[1]] Name", "[Users[1]] Age", "Add more?",
+	}
+	if len(prompter.Prompts) != len(expectedPrompts) {
+		t.Fatalf("expected prompts %q, got %q", expectedPrompts, prompter.Prompts)
+	}
+	for i, prompt := range expectedPrompts {
+		if prompter.Prompts[i] != prompt {
+			t.Fatalf("expected prompts %q, got %q", expectedPrompts, prompter.Prompts)
+		}
+	}
+}
+
+func TestParseTemplateNested(t *testing.T) {
+	tmpl := model.Template{
+		Structures: map[string][]model.Variable{
+			"person": {
+				{Name: "Name", Type: "text"},
+			},
+			"service": {
+				{Name: "Name", Type: "text"},
+				{Name: "Ports", Type: "number[]"},
+				{Name: "Owner", Type: "person"},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Services", Type: "service[]"},
+		},
+		Template: `{{ range .Services }}{{ .Name }}:{{ range .Ports }}{{ . }},{{ end }}{{ .Owner.Name }};{{ end }}`,
+	}
+
+	prompter := NewScriptedPrompter(
+		"api", "80", true, "443", false, "John", true,
+		"db", "5432", false, "Jane", false,
+	)
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "api:80,443,John;db:5432,Jane;"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+
+	expectedPrompts := []string{
+		"[Services[0]] Name", "[Services[0]] Ports", "Add more?", "[Services[0]] Ports", "Add more?", "[Services[0].Owner] Name", "Add more?",
+		"[Services[1]] Name", "[Services[1]] Ports", "Add more?", "[Services[1].Owner] Name", "Add more?",
+	}
+	if len(prompter.Prompts) != len(expectedPrompts) {
+		t.Fatalf("expected prompts %q, got %q", expectedPrompts, prompter.Prompts)
+	}
+	for i, prompt := range expectedPrompts {
+		if prompter.Prompts[i] != prompt {
+			t.Fatalf("expected prompts %q, got %q", expectedPrompts, prompter.Prompts)
+		}
+	}
+}
+
+func TestParseTemplateFieldCondition(t *testing.T) {
+	tmpl := model.Template{
+		Structures: map[string][]model.Variable{
+			"user": {
+				{Name: "Name", Type: "text"},
+				{Name: "IsAdmin", Type: "boolean"},
+				{Name: "Permissions", Type: "text", Condition: "IsAdmin"},
+				{Name: "Pager", Type: "text", Condition: `IsAdmin && $root.Env == "prod"`},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Env", Type: "text"},
+			{Name: "Users", Type: "user[]"},
+		},
+		Template: `{{ range .Users }}{{ .Name }}{{ with .Permissions }}:{{ . }}{{ end }}{{ with .Pager }}:{{ . }}{{ end }};{{ end }}`,
+	}
+
+	prompter := NewScriptedPrompter(
+		"prod",
+		"John", true, "all", "555", true,
+		"Jane", false, false,
+	)
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "John:all:555;Jane;"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestParseTemplateFieldConditionTypes(t *testing.T) {
+	tmpl := model.Template{
+		Structures: map[string][]model.Variable{
+			"event": {
+				{Name: "When", Type: "date"},
+				{Name: "Seats", Type: "integer"},
+				{Name: "Ticket", Type: "text", Condition: "When.Year() >= 2024 && Seats > 10"},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Event", Type: "event"},
+		},
+		Template: `{{ .Event.Ticket }}`,
+	}
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(NewScriptedPrompter("2025-05-01", "20", "vip")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "vip" {
+		t.Fatalf("expected %q, got %q", "vip", result)
+	}
+
+	expected := map[string]bool{"Event.Ticket": true}
+	if visible := Visibility(tmpl); !reflect.DeepEqual(visible, expected) {
+		t.Fatalf("expected %v, got %v", expected, visible)
+	}
+}
+
+func TestParseTemplateConstraints(t *testing.T) {
+	minLength, items := 3, 2
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Replicas", Type: "number", Max: 10},
+			{Name: "Name", Type: "text", MinLength: &minLength},
+			{Name: "Tags", Type: "text[]", MinItems: &items, MaxItems: &items},
+		},
+		Template: `{{ .Replicas }} {{ .Name }}{{ range .Tags }} {{ . }}{{ end }}`,
+	}
+
+	prompter := NewScriptedPrompter("9999", "5", "ab", "abc", "a", "b")
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "5 abc a b"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+
+	expectedErrors := []string{"value must be at most 10", "value must be at least 3 characters long"}
+	if !reflect.DeepEqual(prompter.Errors, expectedErrors) {
+		t.Fatalf("expected errors %q, got %q", expectedErrors, prompter.Errors)
+	}
+
+	expectedPrompts := []string{"Replicas", "Replicas", "Name", "Name", "Tags", "Tags"}
+	if !reflect.DeepEqual(prompter.Prompts, expectedPrompts) {
+		t.Fatalf("expected prompts %q, got %q", expectedPrompts, prompter.Prompts)
+	}
+}
+
+func TestParseTemplateInvalidInput(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Age", Type: "number"},
+			{Name: "Username", Type: "text", Regex: "^[a-z]+$", ErrorMessage: "only lowercase letters"},
+		},
+		Template: `{{ .Age }} {{ .Username }}`,
+	}
+
+	prompter := NewScriptedPrompter("abc", "42", "ABC", "abc")
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "42 abc"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+
+	expectedErrors := []string{"abc is not a number", "only lowercase letters"}
+	if !reflect.DeepEqual(prompter.Errors, expectedErrors) {
+		t.Fatalf("expected errors %q, got %q", expectedErrors, prompter.Errors)
+	}
+}
+
+func TestParseTemplateInteger(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Replicas", Type: "integer"},
+			{Name: "Port", Type: "integer", Default: uint64(8080)},
+		},
+		Template: `{{ printf "%T %v" .Replicas .Replicas }} {{ printf "%T %v" .Port .Port }}`,
+	}
+
+	prompter := NewScriptedPrompter("2.5", "3", "")
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "int64 3 int64 8080"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+
+	expectedErrors := []string{"2.5 is not an integer"}
+	if !reflect.DeepEqual(prompter.Errors, expectedErrors) {
+		t.Fatalf("expected errors %q, got %q", expectedErrors, prompter.Errors)
+	}
+}
+
+func TestParseTemplateTimes(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Due", Type: "date", Format: "02.01.2006"},
+			{Name: "Timeout", Type: "duration", Default: "1d"},
+		},
+		Template: `{{ date "2006-01-02" .Due }} {{ .Timeout.Hours }}`,
+	}
+
+	prompter := NewScriptedPrompter("2024-12-24", "24.12.2024", "")
+
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "2024-12-24 24"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+
+	expectedErrors := []string{"2024-12-24 is not a date in the format 02.01.2006"}
+	if !reflect.DeepEqual(prompter.Errors, expectedErrors) {
+		t.Fatalf("expected errors %q, got %q", expectedErrors, prompter.Errors)
+	}
+}
+
+func TestParseTemplateNoAnswerLeft(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Name", Type: "text"},
+		},
+		Template: `{{ .Name }}`,
+	}
+
+	_, err := ParseTemplate(tmpl, WithPrompter(NewScriptedPrompter()))
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestParseTemplateWithDefaults(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Name", Type: "text", Default: "World"},
+			{Name: "Animal", Type: "select", Options: []model.Option{{Name: "Cat", Value: "cat"}, {Name: "Dog", Value: "dog"}}},
+		},
+		Template: `{{ .Name }} {{ .Animal }}`,
+	}
+
+	prompter := NewScriptedPrompter(nil, nil)
+	tmpl, err := ParseTemplate(tmpl, WithPrompter(prompter), WithDefaults(map[string]any{"Name": "John", "Animal": "dog"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "John dog" {
+		t.Fatalf("expected %q, got %q", "John dog", result)
+	}
+}
+
+func TestFillTemplateOptionDefaults(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Env", Type: "select", Default: "Production", Options: []model.Option{{Name: "Staging", Value: "stage"}, {Name: "Production", Value: "prod"}}},
+			{Name: "Langs", Type: "multiselect", Default: []any{"Go", "Rust"}, Options: []model.Option{{Name: "Go", Value: "go"}, {Name: "Rust", Value: "rs"}}},
+			{Name: "Tools", Type: "multiselect", Default: "Make;Task", Options: []model.Option{{Name: "Make", Value: "make"}, {Name: "Task", Value: "task"}}},
+		},
+		Template: `{{ .Env }}{{ range .Langs }} {{ . }}{{ end }}{{ range .Tools }} {{ . }}{{ end }}`,
+	}
+
+	tmpl, err := FillTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := RenderTemplate(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "prod go rs make task"
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+}
+
+func TestFillTemplateWithoutEnv(t *testing.T) {
+	t.Setenv("GTTP_TEST_SECRET", "hunter2")
+
+	newTemplate := func() model.Template {
+		return model.Template{
+			Variables: []model.Variable{
+				{Name: "Secret", Type: "text", Env: "GTTP_TEST_SECRET"},
+			},
+			Template: `{{ .Secret }}`,
+		}
+	}
+
+	filled, err := FillTemplate(newTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled.Variables[0].Value != "hunter2" {
+		t.Fatalf("expected value from environment, got %v", filled.Variables[0].Value)
+	}
+
+	_, err = FillTemplate(newTemplate(), WithoutEnv())
+	var missing MissingValuesError
+	if !errors.As(err, &missing) || !reflect.DeepEqual(missing.Variables, []string{"Secret"}) {
+		t.Fatalf("expected Secret to be missing, got %v", err)
+	}
+}
+
+func TestParseTemplateEntered(t *testing.T) {
+	t.Setenv("GTTP_Token", "secret")
+
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Name", Type: "text"},
+			{Name: "Token", Type: "text"},
+			{Name: "Region", Type: "text", Value: "eu"},
+		},
+		Template: `{{ .Name }} {{ .Token }} {{ .Region }}`,
+	}
+
+	entered := make(map[string]any)
+	_, err := ParseTemplate(tmpl, WithPrompter(NewScriptedPrompter("Marvin")), WithEnvPrefix("GTTP_"), WithEntered(entered))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{"Name": "Marvin"}
+	if !reflect.DeepEqual(entered, expected) {
+		t.Fatalf("expected %v, got %v", expected, entered)
+	}
+}
+
+func TestVisibility(t *testing.T) {
+	tmpl := model.Template{
+		Structures: map[string][]model.Variable{
+			"user": {
+				{Name: "Admin", Type: "boolean"},
+				{Name: "Email", Type: "text", Condition: `Admin and $root.Env in ["prod"]`},
+			},
+		},
+		Variables: []model.Variable{
+			{Name: "Env", Type: "text", Value: "prod"},
+			{Name: "Debug", Type: "boolean", Condition: `Env matches "^dev"`},
+			{Name: "Users", Type: "user[]", Value: []any{
+				map[string]any{"Admin": true},
+				map[string]any{"Admin": false},
+			}},
+		},
+	}
+
+	expected := map[string]bool{
+		"Debug":          false,
+		"Users[0].Email": true,
+		"Users[1].Email": false,
+	}
+	if visible := Visibility(tmpl); !reflect.DeepEqual(visible, expected) {
+		t.Fatalf("expected %v, got %v", expected, visible)
+	}
+}
+
+func TestRenderFiles(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Name", Type: "text", Value: "app"},
+			{Name: "Docker", Type: "boolean", Value: false},
+		},
+		Files: []model.File{
+			{Path: "{{ .Name }}/README.md", Content: "# {{ .Name }}"},
+			{Path: "{{ .Name }}/Dockerfile", Content: "FROM scratch", Condition: "Docker"},
+		},
+	}
+
+	files, err := RenderFiles(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 1 {
+		t.Fatalf("expected 1 file, got %d", len(files))
+	}
+
+	if files[0].Path != filepath.Join("app", "README.md") || files[0].Content != "# app" {
+		t.Fatalf("unexpected file: %#v", files[0])
+	}
+
+	tmpl.Files = []model.File{{Path: "../{{ .Name }}", Content: ""}}
+	if _, err := RenderFiles(tmpl); err == nil {
+		t.Fatal("expected error for path outside of the output directory, got nil")
+	}
+}
+
+func TestRenderFilesEmptyName(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Dir", Type: "text", Value: ""},
+			{Name: "Docker", Type: "boolean", Value: false},
+		},
+		Files: []model.File{
+			{Path: "{{ if .Docker }}Dockerfile{{ end }}", Content: "FROM scratch"},
+			{Path: "app/{{ if .Docker }}Dockerfile{{ end }}", Content: "FROM scratch"},
+			{Path: "README.md", Content: "# App"},
+		},
+	}
+
+	// Files with an empty file name are skipped
+	files, err := RenderFiles(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "README.md" {
+		t.Fatalf("expected only README.md, got %#v", files)
+	}
+
+	// Files with an empty directory name fail
+	tmpl.Files = []model.File{{Path: "{{ .Dir }}/main.go", Content: "package main"}}
+	if _, err := RenderFiles(tmpl); err == nil || !strings.Contains(err.Error(), "empty directory name") {
+		t.Fatalf("expected error for empty directory name, got %v", err)
+	}
+
+	tmpl.Files = []model.File{{Path: "cmd/{{ .Dir }}/main.go", Content: "package main"}}
+	if _, err := RenderFiles(tmpl); err == nil || !strings.Contains(err.Error(), "empty directory name") {
+		t.Fatalf("expected error for empty directory name, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"github.com/pterm/pterm"
+)
+
+// Prompter asks the user for input.
+// It is used by ParseTemplate to fill variables without a value.
+type Prompter interface {
+	// Text asks for a text. An empty answer means that no value was entered.
+	Text(prompt string, defaultValue string, multiline bool) (string, error)
+	// Number asks for a number and returns the raw answer. An empty answer means that no value was entered.
+	Number(prompt string, defaultValue string) (string, error)
+	// Confirm asks a yes/no question.
+	Confirm(prompt string, defaultValue bool) (bool, error)
+	// Select asks to select one of the options and returns the selected option.
+	Select(prompt string, options []string, defaultOption string) (string, error)
+	// MultiSelect asks to select any number of the options and returns the selected options.
+	MultiSelect(prompt string, options []string, defaultOptions []string) ([]string, error)
+	// Section prints a section header.
+	Section(title string) error
+	// Error shows why an entered value is invalid, before the value is asked for again.
+	Error(message string) error
+}
+
+// PtermPrompter asks for input in the terminal using pterm.
+type PtermPrompter struct{}
+
+func (PtermPrompter) Text(prompt string, defaultValue string, multiline bool) (string, error) {
+	return pterm.DefaultInteractiveTextInput.WithMultiLine(multiline).WithDefaultValue(defaultValue).Show(prompt)
+}
+
+func (PtermPrompter) Number(prompt string, defaultValue string) (string, error) {
+	return pterm.DefaultInteractiveTextInput.WithDefaultValue(defaultValue).Show(prompt)
+}
+
+func (PtermPrompter) Confirm(prompt string, defaultValue bool) (bool, error) {
+	return pterm.DefaultInteractiveConfirm.WithDefaultValue(defaultValue).Show(prompt)
+}
+
+func (PtermPrompter) Select(prompt string, options []string, defaultOption string) (string, error) {
+	return pterm.DefaultInteractiveSelect.WithOptions(options).WithDefaultOption(defaultOption).Show(prompt)
+}
+
+func (PtermPrompter) MultiSelect(prompt string, options []string, defaultOptions []string) ([]string, error) {
+	return pterm.DefaultInteractiveMultiselect.WithOptions(options).WithDefaultOptions(defaultOptions).Show(prompt)
+}
+
+func (PtermPrompter) Section(title string) error {
+	pterm.DefaultSection.Println(title)
+	return nil
+}
+
+func (PtermPrompter) Error(message string) error {
+	pterm.Error.Println(message)
+	return nil
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ScriptedPrompter replays predefined answers in order, instead of asking the user.
+// It can be used to drive ParseTemplate from code, e.g. in tests.
+//
+// Answers are strings for text, number and select prompts, booleans for confirm prompts
+// and string slices for multiselect prompts. A nil answer accepts the default value.
+// Section prompts do not consume an answer.
+type ScriptedPrompter struct {
+	// Answers are the answers given to the prompts, in order.
+	Answers []any
+	// Prompts are the prompts that were asked, in order.
+	Prompts []string
+	// Errors are the errors that were shown for invalid answers, in order.
+	Errors []string
+}
+
+// NewScriptedPrompter creates a ScriptedPrompter that replays the given answers.
+func NewScriptedPrompter(answers ...any) *ScriptedPrompter {
+	return &ScriptedPrompter{Answers: answers}
+}
+
+func (s *ScriptedPrompter) next(prompt string) (any, error) {
+	s.Prompts = append(s.Prompts, prompt)
+	if len(s.Answers) == 0 {
+		return nil, fmt.Errorf("no answer left for prompt %q", prompt)
+	}
+
+	answer := s.Answers[0]
+	s.Answers = s.Answers[1:]
+
+	return answer, nil
+}
+
+func (s *ScriptedPrompter) Text(prompt string, defaultValue string, _ bool) (string, error) {
+	answer, err := s.next(prompt)
+	if err != nil || answer == nil {
+		return defaultValue, err
+	}
+
+	return fmt.Sprint(answer), nil
+}
+
+func (s *ScriptedPrompter) Number(prompt string, defaultValue string) (string, error) {
+	return s.Text(prompt, defaultValue, false)
+}
+
+func (s *ScriptedPrompter) Confirm(prompt string, defaultValue bool) (bool, error) {
+	answer, err := s.next(prompt)
+	if err != nil || answer == nil {
+		return defaultValue, err
+	}
+
+	switch a := answer.(type) {
+	case bool:
+		return a, nil
+	case string:
+		return strconv.ParseBool(a)
+	}
+
+	return false, fmt.Errorf("invalid answer for prompt %q: expected a boolean, got %T", prompt, answer)
+}
+
+func (s *ScriptedPrompter) Select(prompt string, options []string, defaultOption string) (string, error) {
+	answer, err := s.next(prompt)
+	if err != nil || answer == nil {
+		return defaultOption, err
+	}
+
+	for _, option := range options {
+		if option == fmt.Sprint(answer) {
+			return option, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid answer for prompt %q: %v is not an option", prompt, answer)
+}
+
+func (s *ScriptedPrompter) MultiSelect(prompt string, options []string, defaultOptions []string) ([]string, error) {
+	answer, err := s.next(prompt)
+	if err != nil || answer == nil {
+		return defaultOptions, err
+	}
+
+	var selected []string
+	switch a := answer.(type) {
+	case []string:
+		selected = a
+	case []any:
+		for _, item := range a {
+			selected = append(selected, fmt.Sprint(item))
+		}
+	default:
+		return nil, fmt.Errorf("invalid answer for prompt %q: expected a list, got %T", prompt, answer)
+	}
+
+	for _, item := range selected {
+		found := false
+		for _, option := range options {
+			if option == item {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("invalid answer for prompt %q: %s is not an option", prompt, item)
+		}
+	}
+
+	return selected, nil
+}
+
+func (s *ScriptedPrompter) Section(string) error {
+	return nil
+}
+
+func (s *ScriptedPrompter) Error(message string) error {
+	s.Errors = append(s.Errors, message)
+	return nil
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"strings"
+)
+
+// Visibility evaluates the conditions of the variables and structure fields against the values of the template,
+// in the same way as when asking for input. It returns whether each value with a condition is used, keyed by its path,
+// e.g. "Admin" or "Users[1].Email". Values whose condition is not met are treated as not set by later conditions.
+func Visibility(template model.Template) map[string]bool {
+	// Copy variables to not modify the variables of the given template
+	template.Variables = append([]model.Variable(nil), template.Variables...)
+
+	visible := make(map[string]bool)
+	for i, variable := range template.Variables {
+		if variable.Condition != "" {
+			visible[variable.Name] = evaluateCondition(variable.Condition, conditionEnv(nil, template))
+			if !visible[variable.Name] {
+				template.Variables[i].Value = nil
+				continue
+			}
+		}
+
+		fieldVisibility(visible, variable, variable.Value, variable.Name, template)
+	}
+
+	return visible
+}
+
+// fieldVisibility evaluates the conditions of the structure fields inside the value of the variable.
+func fieldVisibility(visible map[string]bool, variable model.Variable, value any, path string, template model.Template) {
+	if variable.IsArray || strings.HasSuffix(variable.Type, "[]") {
+		items, ok := value.([]any)
+		if !ok {
+			return
+		}
+
+		item := variable
+		item.IsArray = false
+		item.Type = strings.TrimSuffix(variable.Type, "[]")
+		for i := range items {
+			fieldVisibility(visible, item, items[i], fmt.Sprintf("%s[%d]", path, i), template)
+		}
+		return
+	}
+
+	fields, ok := template.Structures[variable.Type]
+	if !ok {
+		return
+	}
+
+	m, _ := value.(map[string]any)
+	siblings := make(map[string]any, len(fields))
+	for _, field := range fields {
+		fieldPath := path + "." + field.Name
+		if field.Condition != "" {
+			visible[fieldPath] = evaluateCondition(field.Condition, conditionEnv(siblings, template))
+			if !visible[fieldPath] {
+				siblings[field.Name] = nil // Condition not met, skip field.
+				continue
+			}
+		}
+
+		field.Value = m[field.Name]
+		siblings[field.Name] = template.TypedValue(field)
+		fieldVisibility(visible, field, m[field.Name], fieldPath, template)
+	}
+}
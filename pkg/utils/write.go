@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the content to the specified file and creates all missing parent directories.
+// The file gets the permission perm. If perm is 0, new files are created with 0644 and existing files keep their permission.
+func WriteFile(file string, content []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	if perm == 0 {
+		return os.WriteFile(file, content, 0644)
+	}
+
+	if err := os.WriteFile(file, content, perm); err != nil {
+		return err
+	}
+
+	// The permission of existing files is not changed by WriteFile, and new files are subject to the umask
+	return os.Chmod(file, perm)
+}
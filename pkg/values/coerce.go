@@ -0,0 +1,224 @@
+package values
+
+import (
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Coerce converts a raw value, e.g. from a values file, to the declared type of the variable.
+// Values that cannot be converted are returned unchanged, so that validation reports them.
+func Coerce(variable model.Variable, raw any, structures map[string][]model.Variable) any {
+	return unwrap(coerce(variable, raw, structures))
+}
+
+func coerce(variable model.Variable, raw any, structures map[string][]model.Variable) any {
+	if raw == nil {
+		return nil
+	}
+
+	if strings.HasSuffix(variable.Type, "[]") {
+		variable.IsArray = true
+		variable.Type = strings.TrimSuffix(variable.Type, "[]")
+	}
+
+	if !variable.IsArray {
+		return coerceSingle(variable, raw, structures)
+	}
+
+	items, ok := toSlice(raw)
+	if !ok {
+		return raw
+	}
+
+	values := make([]any, len(items))
+	for i, item := range items {
+		values[i] = coerceSingle(variable, item, structures)
+	}
+
+	return values
+}
+
+func coerceSingle(variable model.Variable, raw any, structures map[string][]model.Variable) any {
+	if _, ok := raw.(literal); ok {
+		return raw // Literals are never coerced.
+	}
+
+	if fields, ok := structures[variable.Type]; ok {
+		return coerceStructure(fields, raw, structures)
+	}
+
+	switch variable.Type {
+	case "text":
+		switch raw.(type) {
+		case map[string]any, []any:
+			return raw
+		}
+		return fmt.Sprint(raw)
+	case "number":
+		if number, ok := toFloat(raw); ok {
+			return number
+		}
+	case "integer":
+		if integer, ok := toInt(raw); ok {
+			return integer
+		}
+	case "boolean":
+		if s, ok := raw.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b
+			}
+		}
+	case "date", "datetime", "time":
+		// Relative values like "+7d" are resolved, so that the value does not change when it is used later, e.g. replayed
+		if s, ok := raw.(string); ok {
+			if t, err := variable.ParseTime(s, time.Now()); err == nil {
+				return t.Format(variable.Layout())
+			}
+		}
+	case "select":
+		return resolveOption(variable.Options, raw)
+	case "multiselect":
+		if s, ok := raw.(string); ok && strings.Contains(s, ";") {
+			raw = strings.Split(s, ";") // Same as defaults of multiselect variables
+		}
+
+		items, ok := toSlice(raw)
+		if !ok {
+			return raw
+		}
+
+		values := make([]string, len(items))
+		for i, item := range items {
+			values[i] = fmt.Sprint(resolveOption(variable.Options, item))
+		}
+		return values
+	}
+
+	return raw
+}
+
+func coerceStructure(fields []model.Variable, raw any, structures map[string][]model.Variable) any {
+	if s, ok := raw.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "{") {
+		var m map[string]any
+		if err := yaml.Unmarshal([]byte(s), &m); err == nil {
+			raw = m
+		}
+	}
+
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return raw
+	}
+
+	values := make(map[string]any, len(m))
+	for key, value := range m {
+		values[key] = value
+		for _, field := range fields {
+			if field.Name == key {
+				values[key] = coerce(field, value, structures)
+				break
+			}
+		}
+	}
+
+	return values
+}
+
+// resolveOption returns the value of the option with the given name.
+// If no option matches, the raw value is returned.
+func resolveOption(options []model.Option, raw any) any {
+	for _, option := range options {
+		if option.Name == fmt.Sprint(raw) {
+			return option.ResolvedValue()
+		}
+	}
+
+	return raw
+}
+
+func toFloat(raw any) (float64, bool) {
+	switch n := raw.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+// toInt converts a raw value to an integer. Floats must not have a fraction.
+func toInt(raw any) (int64, bool) {
+	if s, ok := raw.(string); ok {
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		return i, err == nil
+	}
+
+	f, ok := toFloat(raw)
+	if !ok || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// toSlice converts a raw value to a slice.
+// Strings are either parsed as a YAML flow sequence (e.g. "[a, b]") or split by commas.
+func toSlice(raw any) ([]any, bool) {
+	switch v := raw.(type) {
+	case []any:
+		return v, true
+	case []string:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = item
+		}
+		return items, true
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "[") {
+			var items []any
+			if err := yaml.Unmarshal([]byte(v), &items); err == nil {
+				return items, true
+			}
+		}
+
+		var items []any
+		if strings.TrimSpace(v) == "" {
+			return items, true
+		}
+
+		for _, item := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+		return items, true
+	}
+
+	return nil, false
+}
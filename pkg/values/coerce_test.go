@@ -0,0 +1,60 @@
+package values
+
+import (
+	"github.com/gttp-cli/gttp/pkg/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCoerce(t *testing.T) {
+	structures := map[string][]model.Variable{
+		"user": {
+			{Name: "Name", Type: "text"},
+			{Name: "Age", Type: "integer"},
+			{Name: "Admin", Type: "boolean"},
+		},
+	}
+	options := []model.Option{{Name: "Go", Value: "go"}, {Name: "Rust", Value: "rs"}}
+
+	tests := []struct {
+		name     string
+		variable model.Variable
+		raw      any
+		expected any
+	}{
+		{"number", model.Variable{Type: "number"}, "13.37", 13.37},
+		{"integer", model.Variable{Type: "integer"}, "42", int64(42)},
+		{"integer with fraction", model.Variable{Type: "integer"}, 4.2, 4.2},
+		{"boolean", model.Variable{Type: "boolean"}, "true", true},
+		{"invalid boolean", model.Variable{Type: "boolean"}, "maybe", "maybe"},
+		{"text", model.Variable{Type: "text"}, 42, "42"},
+		{"date", model.Variable{Type: "date"}, "2024-01-02", "2024-01-02"},
+		{"relative date", model.Variable{Type: "date"}, "+7d", time.Now().AddDate(0, 0, 7).Format("2006-01-02")},
+		{"relative date with format", model.Variable{Type: "date", Format: "02.01.2006"}, "now", time.Now().Format("02.01.2006")},
+		{"invalid date", model.Variable{Type: "date"}, "soon", "soon"},
+		{"select", model.Variable{Type: "select", Options: options}, "Rust", "rs"},
+		{"multiselect", model.Variable{Type: "multiselect", Options: options}, "Go;Rust", []string{"go", "rs"}},
+		{"multiselect with commas", model.Variable{Type: "multiselect", Options: options}, "Go,Rust", []string{"go", "rs"}},
+		{"array", model.Variable{Type: "integer[]"}, "[1, 2]", []any{int64(1), int64(2)}},
+		{"array with commas", model.Variable{Type: "text", IsArray: true}, "a, b", []any{"a", "b"}},
+		{
+			"structure",
+			model.Variable{Type: "user"},
+			`{Name: Alice, Age: "30", Admin: "true"}`,
+			map[string]any{"Name": "Alice", "Age": int64(30), "Admin": true},
+		},
+		{
+			"array of structures",
+			model.Variable{Type: "user[]"},
+			[]any{map[string]any{"Name": 1, "Age": "7"}},
+			[]any{map[string]any{"Name": "1", "Age": int64(7)}},
+		},
+	}
+
+	for _, test := range tests {
+		if coerced := Coerce(test.variable, test.raw, structures); !reflect.DeepEqual(coerced, test.expected) {
+			t.Fatalf("%s: expected %#v, got %#v", test.name, test.expected, coerced)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package values
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// literal is a value that is never coerced to the type of its variable.
+type literal string
+
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// Set parses an assignment of the form "path=value" and sets the value in values.
+// Paths use dots for structure fields and brackets for array indices, e.g. "Users[1].Name".
+// The value is coerced to the type of its variable when the values are applied.
+func Set(values map[string]any, assignment string) error {
+	return set(values, assignment, false)
+}
+
+// SetString is like Set, but the value is always used as a string and never coerced.
+func SetString(values map[string]any, assignment string) error {
+	return set(values, assignment, true)
+}
+
+func set(values map[string]any, assignment string, isLiteral bool) error {
+	path, value, ok := strings.Cut(assignment, "=")
+	if !ok {
+		return fmt.Errorf("invalid assignment %q: expected format path=value", assignment)
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return fmt.Errorf("invalid assignment %q: %w", assignment, err)
+	}
+
+	var raw any = value
+	if isLiteral {
+		raw = literal(value)
+	}
+
+	_, err = setPath(values, segments, raw, "")
+	if err != nil {
+		return fmt.Errorf("invalid assignment %q: %w", assignment, err)
+	}
+
+	return nil
+}
+
+func parsePath(path string) ([]pathSegment, error) {
+	var segments []pathSegment
+	for _, part := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key == "" {
+			return nil, fmt.Errorf("empty name in path %q", path)
+		}
+		segments = append(segments, pathSegment{key: key, index: -1})
+
+		for rest != "" {
+			var index string
+			var ok bool
+			index, rest, ok = strings.Cut(rest, "]")
+			if !ok {
+				return nil, fmt.Errorf("missing closing bracket in path %q", path)
+			}
+
+			i, err := strconv.Atoi(index)
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("invalid index %q in path %q", index, path)
+			}
+			segments = append(segments, pathSegment{index: i})
+
+			if rest == "" {
+				break
+			}
+
+			if !strings.HasPrefix(rest, "[") {
+				return nil, fmt.Errorf("unexpected %q in path %q", rest, path)
+			}
+			rest = rest[1:]
+		}
+	}
+
+	return segments, nil
+}
+
+// setPath sets the value at the path of the segments in the container and returns the updated container.
+// The path is the path of the container, used in errors.
+func setPath(container any, segments []pathSegment, value any, path string) (any, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	segment := segments[0]
+	if segment.index < 0 {
+		if path != "" {
+			path += "."
+		}
+		path += segment.key
+
+		m, ok := container.(map[string]any)
+		if !ok {
+			if container != nil {
+				return nil, fmt.Errorf("%s is not a structure", segment.key)
+			}
+			m = make(map[string]any)
+		}
+
+		v, err := setPath(m[segment.key], segments[1:], value, path)
+		if err != nil {
+			return nil, err
+		}
+		m[segment.key] = v
+
+		return m, nil
+	}
+
+	s, ok := container.([]any)
+	if !ok && container != nil {
+		return nil, fmt.Errorf("index %d used on a value that is not an array", segment.index)
+	}
+
+	// Items must be set in order, gaps would be missing values
+	if segment.index > len(s) {
+		return nil, fmt.Errorf("index %d out of range, set %s[%d] first", segment.index, path, len(s))
+	}
+	if segment.index == len(s) {
+		s = append(s, nil)
+	}
+
+	v, err := setPath(s[segment.index], segments[1:], value, fmt.Sprintf("%s[%d]", path, segment.index))
+	if err != nil {
+		return nil, err
+	}
+	s[segment.index] = v
+
+	return s, nil
+}
+
+// unwrap replaces all literals in a raw value with plain strings.
+func unwrap(raw any) any {
+	switch v := raw.(type) {
+	case literal:
+		return string(v)
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[key] = unwrap(value)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, value := range v {
+			s[i] = unwrap(value)
+		}
+		return s
+	}
+
+	return raw
+}
@@ -0,0 +1,39 @@
+package values
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	values := make(map[string]any)
+	for _, assignment := range []string{"Name=Marvin", "Users[0].Name=Alice", "Users[1].Name=Bob", "Users[1].Tags[0]=admin"} {
+		if err := Set(values, assignment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[string]any{
+		"Name": "Marvin",
+		"Users": []any{
+			map[string]any{"Name": "Alice"},
+			map[string]any{"Name": "Bob", "Tags": []any{"admin"}},
+		},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected %v, got %v", expected, values)
+	}
+}
+
+func TestSetIndexGap(t *testing.T) {
+	values := make(map[string]any)
+	err := Set(values, "Users[1].Name=Bob")
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "index 1 out of range, set Users[0] first") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
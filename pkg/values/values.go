@@ -0,0 +1,116 @@
+package values
+
+import (
+	"errors"
+	"fmt"
+	"github.com/goccy/go-yaml"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"github.com/gttp-cli/gttp/pkg/utils"
+	"os"
+	"sort"
+)
+
+// ReadFiles reads YAML or JSON values files and merges them in order.
+// Values of later files override values of earlier files.
+func ReadFiles(files ...string) (map[string]any, error) {
+	values := make(map[string]any)
+	for _, file := range files {
+		content, err := utils.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var fileValues map[string]any
+		err = yaml.Unmarshal([]byte(content), &fileValues)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse values file %s: %w", file, err)
+		}
+
+		Merge(values, fileValues)
+	}
+
+	return values, nil
+}
+
+// Merge deeply merges src into dst.
+// Nested maps are merged, all other values of src replace the values of dst.
+func Merge(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, srcIsMap := value.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			Merge(dstMap, srcMap)
+			continue
+		}
+
+		dst[key] = value
+	}
+}
+
+// Apply sets the values of the template variables from the given values.
+// Values are coerced to the declared type of their variable and validated.
+// A model.ValidationError is returned for every value that does not belong to any variable or is invalid.
+func Apply(template model.Template, values map[string]any) (model.Template, error) {
+	// Copy variables to not modify the variables of the given template
+	template.Variables = append([]model.Variable(nil), template.Variables...)
+
+	var errs []error
+	for name, value := range values {
+		found := false
+		for i, variable := range template.Variables {
+			if variable.Name != name {
+				continue
+			}
+
+			template.Variables[i].Value = Coerce(variable, value, template.Structures)
+			errs = append(errs, template.ValidateValue(template.Variables[i])...)
+			found = true
+			break
+		}
+
+		if !found {
+			errs = append(errs, model.ValidationError{Variable: name, Path: name, Message: "unknown variable"})
+		}
+	}
+
+	// Sort errors for a stable output
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+
+	return template, errors.Join(errs...)
+}
+
+// Extract returns the values of all variables of the template that have a value.
+func Extract(template model.Template) map[string]any {
+	values := make(map[string]any)
+	for _, variable := range template.Variables {
+		if variable.Value != nil {
+			values[variable.Name] = variable.Value
+		}
+	}
+
+	return values
+}
+
+// Known returns only the values that belong to a variable of the template.
+func Known(template model.Template, values map[string]any) map[string]any {
+	known := make(map[string]any)
+	for _, variable := range template.Variables {
+		if value, ok := values[variable.Name]; ok {
+			known[variable.Name] = value
+		}
+	}
+
+	return known
+}
+
+// WriteFile writes the values to a YAML file with the permission perm, see utils.WriteFile.
+func WriteFile(file string, values map[string]any, perm os.FileMode) error {
+	y, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode values: %w", err)
+	}
+
+	return utils.WriteFile(file, y, perm)
+}
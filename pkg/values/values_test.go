@@ -0,0 +1,130 @@
+package values
+
+import (
+	"errors"
+	"github.com/gttp-cli/gttp/pkg/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Name", Type: "text"},
+			{Name: "Age", Type: "integer", Max: 150},
+		},
+	}
+
+	applied, err := Apply(tmpl, map[string]any{"Name": "Alice", "Age": "30"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if applied.Variables[1].Value != int64(30) {
+		t.Fatalf("expected coerced value 30, got %#v", applied.Variables[1].Value)
+	}
+	if tmpl.Variables[1].Value != nil {
+		t.Fatal("expected the variables of the given template to be unchanged")
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Age", Type: "integer", Max: 150},
+		},
+	}
+
+	_, err := Apply(tmpl, map[string]any{"Age": "200", "Email": "alice@example.com"})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	var paths, messages []string
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var validationError model.ValidationError
+		if !errors.As(err, &validationError) {
+			t.Fatalf("expected validation error, got %T", err)
+		}
+		paths = append(paths, validationError.Path)
+		messages = append(messages, validationError.Message)
+	}
+
+	expectedPaths := []string{"Age", "Email"}
+	expectedMessages := []string{"value must be at most 150", "unknown variable"}
+	if !reflect.DeepEqual(paths, expectedPaths) || !reflect.DeepEqual(messages, expectedMessages) {
+		t.Fatalf("expected %v %v, got %v %v", expectedPaths, expectedMessages, paths, messages)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	dst := map[string]any{"Name": "Alice", "User": map[string]any{"Name": "Alice", "Admin": true}, "Tags": []any{"a"}}
+	Merge(dst, map[string]any{"User": map[string]any{"Name": "Bob"}, "Tags": []any{"b"}})
+
+	expected := map[string]any{"Name": "Alice", "User": map[string]any{"Name": "Bob", "Admin": true}, "Tags": []any{"b"}}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Fatalf("expected %v, got %v", expected, dst)
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yml":     "Name: Alice\nUser:\n  Name: Alice\n  Admin: true\n",
+		"override.yml": "User:\n  Name: Bob\n",
+		"last.json":    `{"Name": "Carol"}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	values, err := ReadFiles(filepath.Join(dir, "base.yml"), filepath.Join(dir, "override.yml"), filepath.Join(dir, "last.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{"Name": "Carol", "User": map[string]any{"Name": "Bob", "Admin": true}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected %v, got %v", expected, values)
+	}
+
+	if _, err := ReadFiles(filepath.Join(dir, "missing.yml")); err == nil {
+		t.Fatal("expected error for missing file, got nil")
+	}
+}
+
+func TestKnown(t *testing.T) {
+	tmpl := model.Template{
+		Variables: []model.Variable{
+			{Name: "Name", Type: "text"},
+			{Name: "Age", Type: "integer"},
+		},
+	}
+
+	known := Known(tmpl, map[string]any{"Name": "Alice", "Removed": true})
+	expected := map[string]any{"Name": "Alice"}
+	if !reflect.DeepEqual(known, expected) {
+		t.Fatalf("expected %v, got %v", expected, known)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "answers.yml")
+	values := map[string]any{"Name": "Alice", "Tags": []any{"a", "b"}, "User": map[string]any{"Admin": true}}
+
+	if err := WriteFile(file, values, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadFiles(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, values) {
+		t.Fatalf("expected %v, got %v", values, read)
+	}
+}
@@ -0,0 +1,11 @@
+package web
+
+import (
+	_ "embed"
+)
+
+// Index is the HTML form UI that is served by "gttp serve".
+// It uses the /api/v1/form endpoint to build a form for a template and the /api/v1/render endpoint to render it.
+//
+//go:embed index.html
+var Index []byte
@@ -0,0 +1,196 @@
+package writer
+
+import (
+	"fmt"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines that are shown around changes in a unified diff.
+const contextLines = 3
+
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+type edit struct {
+	kind editKind
+	line string
+	// oldLine and newLine are the indices of the line in the old and new content before the edit is applied.
+	oldLine int
+	newLine int
+}
+
+// UnifiedDiff returns a unified diff between the old and the new content.
+// If the contents are equal, an empty string is returned.
+func UnifiedDiff(oldName, newName, oldContent, newContent string) string {
+	if oldContent == newContent {
+		return ""
+	}
+
+	edits := diffLines(splitLines(oldContent), splitLines(newContent))
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, hunk := range hunks(edits) {
+		first := hunk[0]
+		var oldCount, newCount int
+		for _, e := range hunk {
+			if e.kind != editInsert {
+				oldCount++
+			}
+			if e.kind != editDelete {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(&diff, "@@ -%s +%s @@\n", hunkRange(first.oldLine, oldCount), hunkRange(first.newLine, newCount))
+		for _, e := range hunk {
+			switch e.kind {
+			case editEqual:
+				diff.WriteString(" ")
+			case editDelete:
+				diff.WriteString("-")
+			case editInsert:
+				diff.WriteString("+")
+			}
+			diff.WriteString(e.line)
+			diff.WriteString("\n")
+		}
+	}
+
+	return diff.String()
+}
+
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// hunks groups the edits into hunks of changes with surrounding context lines.
+func hunks(edits []edit) [][]edit {
+	var result [][]edit
+
+	i := 0
+	for i < len(edits) {
+		// Find the next change
+		for i < len(edits) && edits[i].kind == editEqual {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := max(i-contextLines, 0)
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != editEqual {
+				end++
+				continue
+			}
+
+			// Merge changes that are separated by only a few unchanged lines
+			next := end
+			for next < len(edits) && edits[next].kind == editEqual {
+				next++
+			}
+			if next == len(edits) || next-end > 2*contextLines {
+				end = min(end+contextLines, len(edits))
+				break
+			}
+			end = next
+		}
+
+		result = append(result, edits[start:end])
+		i = end
+	}
+
+	return result
+}
+
+// diffLines computes the edits between a and b.
+// Every distinct line is mapped to a rune, so that the runes can be diffed with diffmatchpatch.
+func diffLines(a, b []string) []edit {
+	index := make(map[string]rune)
+	var lines []string
+	toRunes := func(content []string) []rune {
+		runes := make([]rune, len(content))
+		for i, line := range content {
+			r, ok := index[line]
+			if !ok {
+				r = lineRune(len(lines))
+				index[line] = r
+				lines = append(lines, line)
+			}
+			runes[i] = r
+		}
+		return runes
+	}
+
+	diffs := diffmatchpatch.New().DiffMainRunes(toRunes(a), toRunes(b), false)
+
+	var edits []edit
+	var oldLine, newLine int
+	for _, d := range diffs {
+		for _, r := range d.Text {
+			e := edit{line: lines[runeLine(r)], oldLine: oldLine, newLine: newLine}
+			switch d.Type {
+			case diffmatchpatch.DiffEqual:
+				e.kind = editEqual
+				oldLine++
+				newLine++
+			case diffmatchpatch.DiffDelete:
+				e.kind = editDelete
+				oldLine++
+			case diffmatchpatch.DiffInsert:
+				e.kind = editInsert
+				newLine++
+			}
+			edits = append(edits, e)
+		}
+	}
+
+	return edits
+}
+
+// surrogates is the range of runes that cannot be encoded in strings, which are skipped when mapping lines to runes.
+const (
+	surrogateMin = 0xD800
+	surrogateMax = 0xDFFF
+)
+
+// lineRune returns the rune of the line with the given index.
+func lineRune(i int) rune {
+	if r := rune(i); r < surrogateMin {
+		return r
+	}
+	return rune(i) + surrogateMax - surrogateMin + 1
+}
+
+// runeLine returns the index of the line of the rune.
+func runeLine(r rune) int {
+	if r > surrogateMax {
+		return int(r - (surrogateMax - surrogateMin + 1))
+	}
+	return int(r)
+}
@@ -0,0 +1,76 @@
+package writer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nB\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:     "new file",
+			old:      "",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "separate hunks",
+			old:      "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:      "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: "--- old\n+++ new\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := UnifiedDiff("old", "new", test.old, test.new)
+			if diff != test.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", test.expected, diff)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLarge(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 5000; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+		if i%10 == 0 {
+			b = append(b, a[i]) // Some lines are shared
+		}
+	}
+
+	// The edits must reconstruct both contents
+	var old, new []string
+	for _, e := range diffLines(a, b) {
+		if e.kind != editInsert {
+			old = append(old, e.line)
+		}
+		if e.kind != editDelete {
+			new = append(new, e.line)
+		}
+	}
+
+	if !reflect.DeepEqual(old, a) || !reflect.DeepEqual(new, b) {
+		t.Fatal("edits do not reconstruct the contents")
+	}
+}
@@ -0,0 +1,164 @@
+package writer
+
+import (
+	"fmt"
+	"github.com/gttp-cli/gttp/pkg/parser"
+	"github.com/gttp-cli/gttp/pkg/utils"
+	"github.com/pterm/pterm"
+	"io"
+	"os"
+)
+
+// ConflictMode defines what happens if an output file already exists with a different content.
+type ConflictMode string
+
+const (
+	// Overwrite overwrites existing files.
+	Overwrite ConflictMode = "overwrite"
+	// Skip keeps existing files.
+	Skip ConflictMode = "skip"
+	// Fail aborts with an error.
+	Fail ConflictMode = "fail"
+	// Prompt asks whether to overwrite existing files.
+	Prompt ConflictMode = "prompt"
+	// Diff shows a unified diff between the existing and the new content and asks whether to overwrite existing files.
+	Diff ConflictMode = "diff"
+)
+
+// ConflictModes are all available conflict modes.
+var ConflictModes = []ConflictMode{Overwrite, Skip, Fail, Prompt, Diff}
+
+// ParseConflictMode parses a conflict mode.
+func ParseConflictMode(mode string) (ConflictMode, error) {
+	for _, m := range ConflictModes {
+		if string(m) == mode {
+			return m, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid conflict mode %q, must be one of %v", mode, ConflictModes)
+}
+
+// Action is what was done with an output file.
+type Action string
+
+const (
+	Created     Action = "created"
+	Overwritten Action = "overwritten"
+	Skipped     Action = "skipped"
+	Unchanged   Action = "unchanged"
+)
+
+// Writer writes output files and handles conflicts with existing files.
+type Writer struct {
+	// OnConflict defines what happens if a file already exists with a different content.
+	OnConflict ConflictMode
+	// DryRun prevents writing files. Write returns the action that would have been taken.
+	DryRun bool
+	// Prompter is used to ask whether existing files should be overwritten.
+	Prompter parser.Prompter
+	// Out is where diffs are printed to.
+	Out io.Writer
+}
+
+// New creates a writer that asks in the terminal and prints to stdout.
+func New(onConflict ConflictMode, dryRun bool) Writer {
+	return Writer{
+		OnConflict: onConflict,
+		DryRun:     dryRun,
+		Prompter:   parser.PtermPrompter{},
+		Out:        os.Stdout,
+	}
+}
+
+// Conflicts checks if the file already exists with a different content.
+func (w Writer) Conflicts(file string, content []byte) bool {
+	existing, err := os.ReadFile(file)
+	return err == nil && string(existing) != string(content)
+}
+
+// Write writes the content to the file, unless the file already exists and the conflict mode prevents it.
+// Missing parent directories are created. The file gets the permission perm, see utils.WriteFile.
+func (w Writer) Write(file string, content []byte, perm os.FileMode) (Action, error) {
+	existing, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return Created, w.write(file, content, perm)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if string(existing) == string(content) {
+		return Unchanged, w.chmod(file, perm)
+	}
+
+	switch w.OnConflict {
+	case Overwrite, "":
+		return Overwritten, w.write(file, content, perm)
+	case Skip:
+		return Skipped, nil
+	case Fail:
+		return "", fmt.Errorf("file %s already exists", file)
+	case Diff:
+		fmt.Fprintln(w.Out, colorDiff(UnifiedDiff(file, file, string(existing), string(content))))
+		fallthrough
+	case Prompt:
+		if w.DryRun {
+			return Overwritten, nil
+		}
+
+		overwrite, err := w.Prompter.Confirm(fmt.Sprintf("Overwrite %s?", file), false)
+		if err != nil {
+			return "", err
+		}
+
+		if !overwrite {
+			return Skipped, nil
+		}
+
+		return Overwritten, w.write(file, content, perm)
+	}
+
+	return "", fmt.Errorf("invalid conflict mode %q", w.OnConflict)
+}
+
+func (w Writer) write(file string, content []byte, perm os.FileMode) error {
+	if w.DryRun {
+		return nil
+	}
+
+	return utils.WriteFile(file, content, perm)
+}
+
+// chmod changes the permission of an existing file to perm, unless perm is 0.
+func (w Writer) chmod(file string, perm os.FileMode) error {
+	if w.DryRun || perm == 0 {
+		return nil
+	}
+
+	info, err := os.Stat(file)
+	if err != nil || info.Mode().Perm() == perm {
+		return err
+	}
+
+	return os.Chmod(file, perm)
+}
+
+func colorDiff(diff string) string {
+	var colored string
+	for _, line := range splitLines(diff) {
+		switch {
+		case len(line) > 0 && line[0] == '+':
+			colored += pterm.FgGreen.Sprint(line)
+		case len(line) > 0 && line[0] == '-':
+			colored += pterm.FgRed.Sprint(line)
+		case len(line) > 1 && line[:2] == "@@":
+			colored += pterm.FgCyan.Sprint(line)
+		default:
+			colored += line
+		}
+		colored += "\n"
+	}
+
+	return colored
+}
@@ -0,0 +1,200 @@
+package writer
+
+import (
+	"bytes"
+	"github.com/gttp-cli/gttp/pkg/parser"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWritePermission(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scripts", "build.sh")
+	w := Writer{OnConflict: Overwrite}
+
+	action, err := w.Write(file, []byte("#!/bin/sh\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != Created {
+		t.Fatalf("expected %s, got %s", Created, action)
+	}
+	assertPermission(t, file, 0755)
+
+	// The permission of unchanged files is updated as well
+	if err := os.Chmod(file, 0644); err != nil {
+		t.Fatal(err)
+	}
+	action, err = w.Write(file, []byte("#!/bin/sh\n"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if action != Unchanged {
+		t.Fatalf("expected %s, got %s", Unchanged, action)
+	}
+	assertPermission(t, file, 0700)
+
+	// Without a permission, existing files keep theirs
+	if _, err := w.Write(file, []byte("echo\n"), 0); err != nil {
+		t.Fatal(err)
+	}
+	assertPermission(t, file, 0700)
+}
+
+func TestWriteConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     ConflictMode
+		answers  []any
+		action   Action
+		err      bool
+		content  string
+		prompted bool
+		diff     bool
+	}{
+		{name: "overwrite", mode: Overwrite, action: Overwritten, content: "new\n"},
+		{name: "skip", mode: Skip, action: Skipped, content: "old\n"},
+		{name: "fail", mode: Fail, err: true, content: "old\n"},
+		{name: "prompt yes", mode: Prompt, answers: []any{true}, action: Overwritten, content: "new\n", prompted: true},
+		{name: "prompt no", mode: Prompt, answers: []any{false}, action: Skipped, content: "old\n", prompted: true},
+		{name: "diff yes", mode: Diff, answers: []any{true}, action: Overwritten, content: "new\n", prompted: true, diff: true},
+		{name: "diff no", mode: Diff, answers: []any{false}, action: Skipped, content: "old\n", prompted: true, diff: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(file, []byte("old\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			prompter := parser.NewScriptedPrompter(test.answers...)
+			w := Writer{OnConflict: test.mode, Prompter: prompter, Out: &out}
+
+			action, err := w.Write(file, []byte("new\n"), 0)
+			if test.err != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+			if action != test.action {
+				t.Fatalf("expected %q, got %q", test.action, action)
+			}
+			assertContent(t, file, test.content)
+
+			var prompts []string
+			if test.prompted {
+				prompts = []string{"Overwrite " + file + "?"}
+			}
+			if !reflect.DeepEqual(prompter.Prompts, prompts) {
+				t.Fatalf("expected prompts %v, got %v", prompts, prompter.Prompts)
+			}
+
+			diff := strings.Contains(out.String(), "-old") && strings.Contains(out.String(), "+new")
+			if diff != test.diff {
+				t.Fatalf("expected diff %v, got %q", test.diff, out.String())
+			}
+		})
+	}
+}
+
+func TestWriteUnchanged(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(file, []byte("same\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged files are no conflict, so the writer neither fails nor asks
+	for _, mode := range ConflictModes {
+		prompter := parser.NewScriptedPrompter()
+		w := Writer{OnConflict: mode, Prompter: prompter, Out: &bytes.Buffer{}}
+
+		action, err := w.Write(file, []byte("same\n"), 0)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if action != Unchanged {
+			t.Fatalf("%s: expected %q, got %q", mode, Unchanged, action)
+		}
+		if len(prompter.Prompts) != 0 {
+			t.Fatalf("%s: expected no prompt, got %v", mode, prompter.Prompts)
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(file, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := Writer{}
+	if w.Conflicts(filepath.Join(dir, "missing.txt"), []byte("new\n")) {
+		t.Fatal("expected missing file to not conflict")
+	}
+	if w.Conflicts(file, []byte("old\n")) {
+		t.Fatal("expected file with the same content to not conflict")
+	}
+	if !w.Conflicts(file, []byte("new\n")) {
+		t.Fatal("expected file with a different content to conflict")
+	}
+}
+
+func TestWriteDryRun(t *testing.T) {
+	for _, mode := range ConflictModes {
+		dir := t.TempDir()
+		existing := filepath.Join(dir, "existing.txt")
+		if err := os.WriteFile(existing, []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// Dry runs never ask, as nothing is written anyway
+		prompter := parser.NewScriptedPrompter()
+		w := Writer{OnConflict: mode, DryRun: true, Prompter: prompter, Out: &bytes.Buffer{}}
+
+		created := filepath.Join(dir, "sub", "created.txt")
+		action, err := w.Write(created, []byte("new\n"), 0755)
+		if err != nil || action != Created {
+			t.Fatalf("%s: expected %q, got %q, %v", mode, Created, action, err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected no directory to be created, got %v", mode, err)
+		}
+
+		if _, err := w.Write(existing, []byte("new\n"), 0755); err != nil && mode != Fail {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		assertContent(t, existing, "old\n")
+		assertPermission(t, existing, 0644)
+
+		if len(prompter.Prompts) != 0 {
+			t.Fatalf("%s: expected no prompt, got %v", mode, prompter.Prompts)
+		}
+	}
+}
+
+func assertContent(t *testing.T, file string, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Fatalf("expected content %q, got %q", expected, content)
+	}
+}
+
+func assertPermission(t *testing.T, file string, expected os.FileMode) {
+	t.Helper()
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != expected {
+		t.Fatalf("expected permission %o, got %o", expected, info.Mode().Perm())
+	}
+}